@@ -0,0 +1,31 @@
+package api
+
+import "k8s.io/apimachinery/pkg/runtime/schema"
+
+var (
+	CatalogEntityGVK = schema.FromAPIVersionAndKind(Group+"/"+Version, "DatadogCatalogEntity")
+)
+
+// DatadogCatalogEntity represents a link to the page for a service in the Software Catalog.
+type DatadogCatalogEntity struct {
+	APIVersion string   `json:"apiVersion,omitempty" yaml:"apiVersion,omitempty"`
+	Kind       string   `json:"kind,omitempty" yaml:"kind,omitempty"`
+	Metadata   Metadata `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+
+	// BaseURL is the base URL for links generated from this template
+	BaseURL string `json:"baseURL,omitempty" yaml:"baseURL,omitempty"`
+
+	// Service is the name of the service. It is the final segment of the path e.g. /services/feserver
+	Service string `json:"service,omitempty" yaml:"service,omitempty"`
+
+	// Tab is the tab to open (e.g. ownership, dependencies, scorecards, performance)
+	// This is the tab query key
+	Tab string `json:"tab,omitempty" yaml:"tab,omitempty"`
+
+	// Env is the environment to show data for
+	// This is the env query key
+	Env string `json:"env,omitempty" yaml:"env,omitempty"`
+
+	// ExtraParams is a map of extra parameters to include in the link
+	ExtraParams map[string]string `json:"extraParams,omitempty" yaml:"extraParams,omitempty"`
+}
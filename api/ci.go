@@ -0,0 +1,59 @@
+package api
+
+import "k8s.io/apimachinery/pkg/runtime/schema"
+
+var (
+	CIGVK = schema.FromAPIVersionAndKind(Group+"/"+Version, "DatadogCI")
+)
+
+const (
+	// CIPagePipelineExecutions is the CI pipeline executions explorer
+	CIPagePipelineExecutions = "pipeline-executions"
+	// CIPageTestRuns is the test runs explorer
+	CIPageTestRuns = "test-runs"
+	// CIPageFlakyTests is the flaky tests page
+	CIPageFlakyTests = "flaky-tests"
+)
+
+// DatadogCI represents a link to the CI Visibility pipeline executions, test runs or flaky tests.
+//
+// Repository, Branch, PipelineName and CommitSHA are facets in the Datadog query. When building a URL
+// they are prepended to Query. When parsing a URL they are extracted from the query.
+type DatadogCI struct {
+	APIVersion string   `json:"apiVersion,omitempty" yaml:"apiVersion,omitempty"`
+	Kind       string   `json:"kind,omitempty" yaml:"kind,omitempty"`
+	Metadata   Metadata `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+
+	// BaseURL is the base URL for links generated from this template
+	BaseURL string `json:"baseURL,omitempty" yaml:"baseURL,omitempty"`
+
+	// Page is the CI Visibility page to link to; one of pipeline-executions, test-runs or flaky-tests.
+	// Defaults to pipeline-executions. It is the final segment of the path e.g. /ci/test-runs
+	Page string `json:"page,omitempty" yaml:"page,omitempty"`
+
+	// Repository is the repository e.g. github.com/jlewi/ddctl
+	// This is the @git.repository.id facet
+	Repository string `json:"repository,omitempty" yaml:"repository,omitempty"`
+
+	// Branch is the git branch
+	// This is the @git.branch facet
+	Branch string `json:"branch,omitempty" yaml:"branch,omitempty"`
+
+	// PipelineName is the name of the CI pipeline
+	// This is the @ci.pipeline.name facet
+	PipelineName string `json:"pipelineName,omitempty" yaml:"pipelineName,omitempty"`
+
+	// CommitSHA is the git commit
+	// This is the @git.commit.sha facet
+	CommitSHA string `json:"commitSHA,omitempty" yaml:"commitSHA,omitempty"`
+
+	// Query is any additional query used to filter the results
+	Query string `json:"query,omitempty" yaml:"query,omitempty"`
+
+	// FromTS is the value of the from_ts query key
+	FromTS string `json:"fromTS,omitempty" yaml:"fromTS,omitempty"`
+	ToTS   string `json:"toTS,omitempty" yaml:"toTS,omitempty"`
+
+	// ExtraParams is a map of extra parameters to include in the link
+	ExtraParams map[string]string `json:"extraParams,omitempty" yaml:"extraParams,omitempty"`
+}
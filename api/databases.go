@@ -0,0 +1,58 @@
+package api
+
+import "k8s.io/apimachinery/pkg/runtime/schema"
+
+var (
+	DatabaseGVK = schema.FromAPIVersionAndKind(Group+"/"+Version, "DatadogDatabase")
+)
+
+const (
+	// DatabasePageQueries is the query metrics page
+	DatabasePageQueries = "queries"
+	// DatabasePageSamples is the query samples page
+	DatabasePageSamples = "samples"
+	// DatabasePageExplainPlans is the explain plans page
+	DatabasePageExplainPlans = "explain-plans"
+	// DatabasePageHosts is the database hosts page
+	DatabasePageHosts = "hosts"
+)
+
+// DatadogDatabase represents a link to a Database Monitoring page
+type DatadogDatabase struct {
+	APIVersion string   `json:"apiVersion,omitempty" yaml:"apiVersion,omitempty"`
+	Kind       string   `json:"kind,omitempty" yaml:"kind,omitempty"`
+	Metadata   Metadata `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+
+	// BaseURL is the base URL for links generated from this template
+	BaseURL string `json:"baseURL,omitempty" yaml:"baseURL,omitempty"`
+
+	// Page is the Database Monitoring page to link to; one of queries, samples, explain-plans or hosts.
+	// Defaults to queries. It is the final segment of the path e.g. /databases/samples
+	Page string `json:"page,omitempty" yaml:"page,omitempty"`
+
+	// Query is the query used to filter the page e.g. "env:prod service:orders-db"
+	Query string `json:"query,omitempty" yaml:"query,omitempty"`
+
+	// QuerySignature selects a single normalized query
+	// This is the query_signature query key
+	QuerySignature string `json:"querySignature,omitempty" yaml:"querySignature,omitempty"`
+
+	// Host selects a single database host
+	// This is the host query key
+	Host string `json:"host,omitempty" yaml:"host,omitempty"`
+
+	// DBMS is the database engine (e.g. postgres, mysql, sqlserver)
+	// This is the dbms query key
+	DBMS string `json:"dbms,omitempty" yaml:"dbms,omitempty"`
+
+	// Sort is the column to sort by
+	// This is the sort query key
+	Sort string `json:"sort,omitempty" yaml:"sort,omitempty"`
+
+	// FromTS is the value of the from_ts query key
+	FromTS string `json:"fromTS,omitempty" yaml:"fromTS,omitempty"`
+	ToTS   string `json:"toTS,omitempty" yaml:"toTS,omitempty"`
+
+	// ExtraParams is a map of extra parameters to include in the link
+	ExtraParams map[string]string `json:"extraParams,omitempty" yaml:"extraParams,omitempty"`
+}
@@ -0,0 +1,42 @@
+package api
+
+import "k8s.io/apimachinery/pkg/runtime/schema"
+
+var (
+	ErrorTrackingGVK = schema.FromAPIVersionAndKind(Group+"/"+Version, "DatadogErrorTracking")
+)
+
+// DatadogErrorTracking represents a link to the Error Tracking explorer or to a single Error Tracking issue.
+type DatadogErrorTracking struct {
+	APIVersion string   `json:"apiVersion,omitempty" yaml:"apiVersion,omitempty"`
+	Kind       string   `json:"kind,omitempty" yaml:"kind,omitempty"`
+	Metadata   Metadata `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+
+	// BaseURL is the base URL for links generated from this template
+	BaseURL string `json:"baseURL,omitempty" yaml:"baseURL,omitempty"`
+
+	// IssueID is the ID of the issue. If it is set the link points to the issue page
+	// (/error-tracking/issue/<id>); otherwise it points to the explorer.
+	IssueID string `json:"issueID,omitempty" yaml:"issueID,omitempty"`
+
+	// Source is the product the errors come from (e.g. logs, apm, rum)
+	// This is the source query key
+	Source string `json:"source,omitempty" yaml:"source,omitempty"`
+
+	// Query is the query used to filter issues
+	Query string `json:"query,omitempty" yaml:"query,omitempty"`
+
+	// Sort is the order of the issues (e.g. TOTAL_COUNT, FIRST_SEEN)
+	// This is the sort query key
+	Sort string `json:"sort,omitempty" yaml:"sort,omitempty"`
+
+	// RefreshMode is the value of the refresh_mode query key
+	RefreshMode string `json:"refreshMode,omitempty" yaml:"refreshMode,omitempty"`
+
+	// FromTS is the value of the from_ts query key
+	FromTS string `json:"fromTS,omitempty" yaml:"fromTS,omitempty"`
+	ToTS   string `json:"toTS,omitempty" yaml:"toTS,omitempty"`
+
+	// ExtraParams is a map of extra parameters to include in the link
+	ExtraParams map[string]string `json:"extraParams,omitempty" yaml:"extraParams,omitempty"`
+}
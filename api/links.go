@@ -6,6 +6,11 @@ var (
 	LinkGVK = schema.FromAPIVersionAndKind(Group+"/"+Version, "DatadogLink")
 )
 
+const (
+	// LogsModeLiveTail is the mode for links to Live Tail (/logs/livetail).
+	LogsModeLiveTail = "liveTail"
+)
+
 // DatadogLink represents a link to a Datadog dashboard
 type DatadogLink struct {
 	APIVersion string   `json:"apiVersion,omitempty" yaml:"apiVersion,omitempty"`
@@ -14,6 +19,12 @@ type DatadogLink struct {
 
 	// BaseURL is the base URL for links generated from this template
 	BaseURL string `json:"baseURL,omitempty" yaml:"baseURL,omitempty"`
+
+	// Mode selects the logs page. The default is the Log Explorer (/logs).
+	// Set it to liveTail to link to Live Tail (/logs/livetail). Live Tail streams logs as they arrive so it
+	// doesn't support a time window or analytics fields.
+	Mode string `json:"mode,omitempty" yaml:"mode,omitempty"`
+
 	// Query is the query to be used in the link
 	Query string `json:"query,omitempty" yaml:"query,omitempty"`
 	// VisualizeAs is the visualization to use for the link
@@ -24,6 +35,14 @@ type DatadogLink struct {
 	// This is the agg_m query key
 	GroupInto string `json:"groupInto,omitempty" yaml:"groupInto,omitempty"`
 
+	// SavedView is the ID of the saved view the link was opened from
+	// This is the saved-view-id query key
+	SavedView string `json:"savedView,omitempty" yaml:"savedView,omitempty"`
+
+	// Indexes is the list of log indexes to search
+	// This is the index query key
+	Indexes []string `json:"indexes,omitempty" yaml:"indexes,omitempty"`
+
 	// Storage is the storage tier to query
 	Storage string `json:"storage,omitempty" yaml:"storage,omitempty"`
 
@@ -51,7 +70,8 @@ type DatadogLink struct {
 	// StreamSort is the value of the stream_sort query key
 	StreamSort string `json:"streamSort,omitempty" yaml:"streamSort,omitempty"`
 
-	// Live is the value of the live query key
+	// Live is the value of the live query key. It controls whether the Log Explorer time window slides with
+	// the current time. It is unrelated to Live Tail; use Mode for that.
 	Live bool `json:"live,omitempty" yaml:"live,omitempty"`
 
 	// TopO specifies the ordering of the top fields
@@ -66,6 +86,14 @@ type DatadogLink struct {
 	// This is the agg_t query key
 	AggType string `json:"aggType,omitempty" yaml:"aggType,omitempty"`
 
+	// Queries are the queries of a multi-query analytics view. Each query is encoded with indexed query keys
+	// e.g. the Measure of the first query is the agg_m_0 query key. Use GroupInto, GroupBy, AggType etc... for
+	// views with a single query.
+	Queries []LogsQuery `json:"queries,omitempty" yaml:"queries,omitempty"`
+
+	// Formulas are the formulas combining Queries e.g. "a / b * 100" where a and b refer to the first and second query.
+	Formulas []LogsFormula `json:"formulas,omitempty" yaml:"formulas,omitempty"`
+
 	// Columns is the columns to display
 	// This is the cols query key
 	Columns []string `json:"columns,omitempty" yaml:"columns,omitempty"`
@@ -85,3 +113,49 @@ type DatadogLink struct {
 	// ExtraParams is a map of extra parameters to include in the link
 	ExtraParams map[string]string `json:"extraParams,omitempty" yaml:"extraParams,omitempty"`
 }
+
+// LogsQuery is one of the queries in a multi-query analytics view.
+// N is the index of the query in DatadogLink.Queries.
+type LogsQuery struct {
+	// Measure is what to aggregate e.g. count or @duration
+	// This is the agg_m_N query key
+	Measure string `json:"measure,omitempty" yaml:"measure,omitempty"`
+
+	// MeasureSource is the value of the agg_m_source_N query key
+	MeasureSource string `json:"measureSource,omitempty" yaml:"measureSource,omitempty"`
+
+	// AggType is the aggregation type (e.g. count, avg, sum, pc99)
+	// This is the agg_t_N query key
+	AggType string `json:"aggType,omitempty" yaml:"aggType,omitempty"`
+
+	// GroupBy is the list of fields to group by
+	// This is the agg_q_N query key; the fields are comma separated
+	GroupBy []string `json:"groupBy,omitempty" yaml:"groupBy,omitempty"`
+
+	// GroupBySource is the value of the agg_q_source_N query key
+	GroupBySource string `json:"groupBySource,omitempty" yaml:"groupBySource,omitempty"`
+
+	// TopN is the number of groups to display
+	// This is the top_n_N query key. It is a pointer so an explicit 0 is preserved.
+	TopN *int `json:"topN,omitempty" yaml:"topN,omitempty"`
+
+	// TopO specifies the ordering of the groups
+	// This is the top_o_N query key
+	TopO string `json:"topO,omitempty" yaml:"topO,omitempty"`
+
+	// Missing specifies the behavior for fields that maybe missing
+	// This is the x_missing_N query key
+	Missing string `json:"missing,omitempty" yaml:"missing,omitempty"`
+}
+
+// LogsFormula is a formula combining the queries of a multi-query analytics view.
+// N is the index of the formula in DatadogLink.Formulas.
+type LogsFormula struct {
+	// Formula is the expression e.g. "a / b * 100"
+	// This is the formula_N query key
+	Formula string `json:"formula,omitempty" yaml:"formula,omitempty"`
+
+	// Alias is the name displayed for the formula
+	// This is the formula_alias_N query key
+	Alias string `json:"alias,omitempty" yaml:"alias,omitempty"`
+}
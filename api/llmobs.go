@@ -0,0 +1,42 @@
+package api
+
+import "k8s.io/apimachinery/pkg/runtime/schema"
+
+var (
+	LLMTraceGVK = schema.FromAPIVersionAndKind(Group+"/"+Version, "DatadogLLMTrace")
+)
+
+// DatadogLLMTrace represents a link to the LLM Observability traces explorer or to a single LLM trace.
+//
+// MLApp and SpanKind are facets in the Datadog query. When building a URL
+// they are prepended to Query. When parsing a URL they are extracted from the query.
+type DatadogLLMTrace struct {
+	APIVersion string   `json:"apiVersion,omitempty" yaml:"apiVersion,omitempty"`
+	Kind       string   `json:"kind,omitempty" yaml:"kind,omitempty"`
+	Metadata   Metadata `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+
+	// BaseURL is the base URL for links generated from this template
+	BaseURL string `json:"baseURL,omitempty" yaml:"baseURL,omitempty"`
+
+	// TraceID is the ID of a trace. If it is set the link opens the trace in the explorer.
+	// This is the traceId query key
+	TraceID string `json:"traceID,omitempty" yaml:"traceID,omitempty"`
+
+	// MLApp is the name of the LLM application
+	// This is the @ml_app facet
+	MLApp string `json:"mlApp,omitempty" yaml:"mlApp,omitempty"`
+
+	// SpanKind is the kind of span (e.g. llm, agent, workflow, tool, retrieval)
+	// This is the @meta.span.kind facet
+	SpanKind string `json:"spanKind,omitempty" yaml:"spanKind,omitempty"`
+
+	// Query is any additional query used to filter the traces
+	Query string `json:"query,omitempty" yaml:"query,omitempty"`
+
+	// FromTS is the value of the from_ts query key
+	FromTS string `json:"fromTS,omitempty" yaml:"fromTS,omitempty"`
+	ToTS   string `json:"toTS,omitempty" yaml:"toTS,omitempty"`
+
+	// ExtraParams is a map of extra parameters to include in the link
+	ExtraParams map[string]string `json:"extraParams,omitempty" yaml:"extraParams,omitempty"`
+}
@@ -0,0 +1,56 @@
+package api
+
+import "k8s.io/apimachinery/pkg/runtime/schema"
+
+var (
+	NetworkGVK = schema.FromAPIVersionAndKind(Group+"/"+Version, "DatadogNetwork")
+)
+
+const (
+	// NetworkPageAnalytics is the network analytics page
+	NetworkPageAnalytics = "analytics"
+	// NetworkPageMap is the network map page
+	NetworkPageMap = "map"
+)
+
+// DatadogNetwork represents a link to the Network Performance Monitoring analytics or network map pages.
+// Traffic is aggregated into flows between a source and a destination which are grouped and filtered independently.
+type DatadogNetwork struct {
+	APIVersion string   `json:"apiVersion,omitempty" yaml:"apiVersion,omitempty"`
+	Kind       string   `json:"kind,omitempty" yaml:"kind,omitempty"`
+	Metadata   Metadata `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+
+	// BaseURL is the base URL for links generated from this template
+	BaseURL string `json:"baseURL,omitempty" yaml:"baseURL,omitempty"`
+
+	// Page is the network page to link to; one of analytics or map. Defaults to analytics.
+	// It is the final segment of the path e.g. /network/map
+	Page string `json:"page,omitempty" yaml:"page,omitempty"`
+
+	// SourceGroupBy is the list of tags used to group the source of the traffic e.g. service
+	// This is the groupby_source query key
+	SourceGroupBy []string `json:"sourceGroupBy,omitempty" yaml:"sourceGroupBy,omitempty"`
+
+	// DestinationGroupBy is the list of tags used to group the destination of the traffic
+	// This is the groupby_dest query key
+	DestinationGroupBy []string `json:"destinationGroupBy,omitempty" yaml:"destinationGroupBy,omitempty"`
+
+	// SourceFilter is the query used to filter the source of the traffic e.g. service:feserver
+	// This is the source_query query key
+	SourceFilter string `json:"sourceFilter,omitempty" yaml:"sourceFilter,omitempty"`
+
+	// DestinationFilter is the query used to filter the destination of the traffic
+	// This is the dest_query query key
+	DestinationFilter string `json:"destinationFilter,omitempty" yaml:"destinationFilter,omitempty"`
+
+	// Metric is the metric to display (e.g. volume_sent, tcp_retransmits, tcp_rtt)
+	// This is the metric query key
+	Metric string `json:"metric,omitempty" yaml:"metric,omitempty"`
+
+	// FromTS is the value of the from_ts query key
+	FromTS string `json:"fromTS,omitempty" yaml:"fromTS,omitempty"`
+	ToTS   string `json:"toTS,omitempty" yaml:"toTS,omitempty"`
+
+	// ExtraParams is a map of extra parameters to include in the link
+	ExtraParams map[string]string `json:"extraParams,omitempty" yaml:"extraParams,omitempty"`
+}
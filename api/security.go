@@ -0,0 +1,46 @@
+package api
+
+import "k8s.io/apimachinery/pkg/runtime/schema"
+
+var (
+	SecuritySignalGVK = schema.FromAPIVersionAndKind(Group+"/"+Version, "DatadogSecuritySignal")
+)
+
+// DatadogSecuritySignal represents a link to the Cloud SIEM security signals explorer or to a single signal.
+//
+// Severity, Status and RuleID are facets in the Datadog query. When building a URL
+// they are prepended to Query. When parsing a URL they are extracted from the query.
+type DatadogSecuritySignal struct {
+	APIVersion string   `json:"apiVersion,omitempty" yaml:"apiVersion,omitempty"`
+	Kind       string   `json:"kind,omitempty" yaml:"kind,omitempty"`
+	Metadata   Metadata `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+
+	// BaseURL is the base URL for links generated from this template
+	BaseURL string `json:"baseURL,omitempty" yaml:"baseURL,omitempty"`
+
+	// SignalID is the ID of a signal. If it is set the link opens the signal in the explorer.
+	// This is the event query key
+	SignalID string `json:"signalID,omitempty" yaml:"signalID,omitempty"`
+
+	// Severity is the severity of the signal (e.g. info, low, medium, high, critical)
+	// This is the status facet
+	Severity string `json:"severity,omitempty" yaml:"severity,omitempty"`
+
+	// Status is the triage state of the signal (e.g. open, under_review, archived)
+	// This is the @workflow.triage.state facet
+	Status string `json:"status,omitempty" yaml:"status,omitempty"`
+
+	// RuleID is the ID of the detection rule that generated the signal
+	// This is the @workflow.rule.id facet
+	RuleID string `json:"ruleID,omitempty" yaml:"ruleID,omitempty"`
+
+	// Query is any additional query used to filter the signals
+	Query string `json:"query,omitempty" yaml:"query,omitempty"`
+
+	// FromTS is the value of the from_ts query key
+	FromTS string `json:"fromTS,omitempty" yaml:"fromTS,omitempty"`
+	ToTS   string `json:"toTS,omitempty" yaml:"toTS,omitempty"`
+
+	// ExtraParams is a map of extra parameters to include in the link
+	ExtraParams map[string]string `json:"extraParams,omitempty" yaml:"extraParams,omitempty"`
+}
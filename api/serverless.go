@@ -0,0 +1,54 @@
+package api
+
+import "k8s.io/apimachinery/pkg/runtime/schema"
+
+var (
+	ServerlessGVK = schema.FromAPIVersionAndKind(Group+"/"+Version, "DatadogServerless")
+)
+
+// DatadogServerless represents a link to the serverless functions list or to the page for a single function.
+//
+// Region, Env and Version are facets in the Datadog query. When building a URL
+// they are prepended to Query. When parsing a URL they are extracted from the query.
+type DatadogServerless struct {
+	APIVersion string   `json:"apiVersion,omitempty" yaml:"apiVersion,omitempty"`
+	Kind       string   `json:"kind,omitempty" yaml:"kind,omitempty"`
+	Metadata   Metadata `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+
+	// BaseURL is the base URL for links generated from this template
+	BaseURL string `json:"baseURL,omitempty" yaml:"baseURL,omitempty"`
+
+	// FunctionName is the name of the function. If FunctionName or ARN is set the link points to the
+	// function's page (/functions/<name>); otherwise it points to the list of functions.
+	// At most one of FunctionName and ARN can be set.
+	FunctionName string `json:"functionName,omitempty" yaml:"functionName,omitempty"`
+
+	// ARN is the ARN of the function e.g. arn:aws:lambda:us-east-1:123456789012:function:checkout
+	ARN string `json:"arn,omitempty" yaml:"arn,omitempty"`
+
+	// Tab is the tab to open on the function's page (e.g. invocations, traces, logs)
+	// This is the tab query key
+	Tab string `json:"tab,omitempty" yaml:"tab,omitempty"`
+
+	// Region is the cloud region of the function
+	// This is the region facet
+	Region string `json:"region,omitempty" yaml:"region,omitempty"`
+
+	// Env is the environment of the function
+	// This is the env facet
+	Env string `json:"env,omitempty" yaml:"env,omitempty"`
+
+	// Version is the version of the function
+	// This is the version facet
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+
+	// Query is any additional query used to filter the functions or invocations
+	Query string `json:"query,omitempty" yaml:"query,omitempty"`
+
+	// FromTS is the value of the from_ts query key
+	FromTS string `json:"fromTS,omitempty" yaml:"fromTS,omitempty"`
+	ToTS   string `json:"toTS,omitempty" yaml:"toTS,omitempty"`
+
+	// ExtraParams is a map of extra parameters to include in the link
+	ExtraParams map[string]string `json:"extraParams,omitempty" yaml:"extraParams,omitempty"`
+}
@@ -0,0 +1,48 @@
+package api
+
+import "k8s.io/apimachinery/pkg/runtime/schema"
+
+var (
+	TraceSearchGVK = schema.FromAPIVersionAndKind(Group+"/"+Version, "DatadogTraceSearch")
+)
+
+// DatadogTraceSearch represents a link to the APM Trace Explorer searching or analyzing spans.
+// Use DatadogTrace to link to a single trace.
+type DatadogTraceSearch struct {
+	APIVersion string   `json:"apiVersion,omitempty" yaml:"apiVersion,omitempty"`
+	Kind       string   `json:"kind,omitempty" yaml:"kind,omitempty"`
+	Metadata   Metadata `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+
+	// BaseURL is the base URL for links generated from this template
+	BaseURL string `json:"baseURL,omitempty" yaml:"baseURL,omitempty"`
+
+	// Query is the span query e.g. service:feserver @http.status_code:>=500
+	Query string `json:"query,omitempty" yaml:"query,omitempty"`
+
+	// VisualizeAs is the visualization e.g. list, timeseries, toplist or table
+	// This is the viz query key
+	VisualizeAs string `json:"viz,omitempty" yaml:"viz,omitempty"`
+
+	// Measure is what to aggregate e.g. count or @duration
+	// This is the agg_m query key
+	Measure string `json:"measure,omitempty" yaml:"measure,omitempty"`
+
+	// AggType is the aggregation type (e.g. count, avg, sum, pc99)
+	// This is the agg_t query key
+	AggType string `json:"aggType,omitempty" yaml:"aggType,omitempty"`
+
+	// GroupBy is the list of fields to group by
+	// This is the agg_q query key; the fields are comma separated
+	GroupBy []string `json:"groupBy,omitempty" yaml:"groupBy,omitempty"`
+
+	// TopN is the number of groups to display
+	// This is the top_n query key
+	TopN int `json:"topN,omitempty" yaml:"topN,omitempty"`
+
+	// FromTS is the value of the from_ts query key
+	FromTS string `json:"fromTS,omitempty" yaml:"fromTS,omitempty"`
+	ToTS   string `json:"toTS,omitempty" yaml:"toTS,omitempty"`
+
+	// ExtraParams is a map of extra parameters to include in the link
+	ExtraParams map[string]string `json:"extraParams,omitempty" yaml:"extraParams,omitempty"`
+}
@@ -0,0 +1,464 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/jlewi/ddctl/pkg/application"
+	"github.com/jlewi/ddctl/pkg/config"
+	"github.com/jlewi/ddctl/pkg/convert"
+	"github.com/jlewi/ddctl/pkg/ddog"
+	"github.com/jlewi/ddctl/pkg/version"
+	gapi "github.com/jlewi/grafctl/api"
+	"github.com/jlewi/monogo/yamlfiles"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	yaml "sigs.k8s.io/yaml/goyaml.v3"
+)
+
+// NewConvertCmd adds commands to convert links from other tools into Datadog links
+func NewConvertCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "convert",
+		Short: "Convert queries and links from other tools into Datadog links",
+	}
+
+	cmd.AddCommand(NewConvertGrafanaCmd())
+	cmd.AddCommand(NewConvertHoneycombCmd())
+	cmd.AddCommand(NewConvertSplunkCmd())
+	cmd.AddCommand(NewConvertKibanaCmd())
+	cmd.AddCommand(NewConvertGCPCmd())
+	return cmd
+}
+
+// convertFlags are the flags shared by the convert commands.
+type convertFlags struct {
+	baseURL string
+	outFile string
+	to      string
+}
+
+func (f *convertFlags) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&f.baseURL, config.BaseURLFlagName, "", "", "The base URL for your Datadog URLs. It should be something like https://acme.datadoghq.com")
+	cmd.Flags().StringVarP(&f.outFile, "output-file", "o", "", "File to write the result to. If not specified the result is written to stdout.")
+	cmd.Flags().StringVarP(&f.to, "to", "", "yaml", "Write the converted links as url or yaml")
+}
+
+// convertedLink is a link produced by a converter and the warnings about what couldn't be translated.
+type convertedLink struct {
+	link     any
+	warnings []convert.Warning
+}
+
+// writeConverted writes the links as YAML or URLs. Warnings are written as comments before each YAML document
+// or to stderr when writing URLs.
+func (f *convertFlags) writeConverted(links []convertedLink) error {
+	var w io.Writer = os.Stdout
+	if f.outFile != "" {
+		o, err := os.Create(f.outFile)
+		if err != nil {
+			return errors.Wrapf(err, "Error creating file %v", f.outFile)
+		}
+		defer o.Close()
+		w = o
+	}
+
+	for i, c := range links {
+		switch f.to {
+		case "url":
+			for _, warning := range c.warnings {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", warning)
+			}
+			u, err := ddog.LinkToURL(c.link)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(w, u)
+		case "yaml":
+			if i > 0 {
+				fmt.Fprintln(w, "---")
+			}
+			for _, warning := range c.warnings {
+				fmt.Fprintf(w, "# Warning: %v\n", warning)
+			}
+			encoder := yaml.NewEncoder(w)
+			encoder.SetIndent(2)
+			if err := encoder.Encode(c.link); err != nil {
+				return errors.Wrapf(err, "Error writing Link")
+			}
+			if err := encoder.Close(); err != nil {
+				return errors.Wrapf(err, "Error writing Link")
+			}
+		default:
+			return errors.Errorf("Unsupported output %v; must be url or yaml", f.to)
+		}
+	}
+	return nil
+}
+
+// NewConvertGrafanaCmd creates a command to convert Grafana Explore links with Loki queries
+func NewConvertGrafanaCmd() *cobra.Command {
+	var grafanaURL string
+	var linksFile string
+	var labelMapping map[string]string
+	flags := &convertFlags{}
+	cmd := &cobra.Command{
+		Use:   "grafana",
+		Short: "Convert Grafana Explore links with LogQL queries into DatadogLinks",
+		Example: `ddctl convert grafana --url=${GRAFANA_URL}
+ddctl convert grafana -f grafana_links.yaml --label-map job=service`,
+		Run: func(cmd *cobra.Command, args []string) {
+			err := func() error {
+				app := application.NewApp()
+				if err := app.LoadConfig(cmd); err != nil {
+					return err
+				}
+				if err := app.SetupLogging(); err != nil {
+					return err
+				}
+				version.LogVersion()
+
+				if (grafanaURL == "") == (linksFile == "") {
+					return errors.New("Exactly one of --url and --filename must be set")
+				}
+				if app.Config.GetBaseURL() == "" {
+					return errors.New("baseURL must be specified either in config.yaml or via the --base-url flag")
+				}
+
+				opts := convert.GrafanaOptions{
+					BaseURL:      app.Config.GetBaseURL(),
+					LabelMapping: convert.MergeMappings(app.Config.GetGrafanaLabelMapping(), labelMapping),
+				}
+
+				var links []convertedLink
+				if grafanaURL != "" {
+					link, warnings, err := convert.GrafanaURLToLink(grafanaURL, opts)
+					if err != nil {
+						return err
+					}
+					links = append(links, convertedLink{link: link, warnings: warnings})
+				} else {
+					nodes, err := yamlfiles.Read(linksFile)
+					if err != nil {
+						return errors.Wrapf(err, "Error reading file %v", linksFile)
+					}
+					for _, n := range nodes {
+						if n.GetKind() != gapi.LinkGVK.Kind {
+							continue
+						}
+						glink := &gapi.GrafanaLink{}
+						if err := n.YNode().Decode(glink); err != nil {
+							return errors.Wrapf(err, "Error decoding %v", n.GetKind())
+						}
+						link, warnings, err := convert.GrafanaLinkToLink(glink, opts)
+						if err != nil {
+							return errors.Wrapf(err, "Failed to convert %v", n.GetName())
+						}
+						links = append(links, convertedLink{link: link, warnings: warnings})
+					}
+				}
+
+				return flags.writeConverted(links)
+			}()
+
+			if err != nil {
+				fmt.Printf("Error running request;\n %+v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(&grafanaURL, "url", "u", "", "The Grafana Explore URL to convert")
+	cmd.Flags().StringVarP(&linksFile, "filename", "f", "", "A YAML file containing GrafanaLink resources to convert")
+	cmd.Flags().StringToStringVarP(&labelMapping, "label-map", "", nil, "Map a Loki label to a Datadog tag e.g. job=service. Extends the grafana.labelMapping config.")
+	flags.addFlags(cmd)
+	return cmd
+}
+
+// NewConvertHoneycombCmd creates a command to convert Honeycomb queries
+func NewConvertHoneycombCmd() *cobra.Command {
+	var honeycombURL string
+	var specFile string
+	var target string
+	var columnMapping map[string]string
+	flags := &convertFlags{}
+	cmd := &cobra.Command{
+		Use:   "honeycomb",
+		Short: "Convert Honeycomb query links and query specifications into DatadogTraceSearch or DatadogLink resources",
+		Example: `ddctl convert honeycomb --url=${HONEYCOMB_URL}
+ddctl convert honeycomb -f query.json --target=logs --column-map app.tenant=tenant`,
+		Run: func(cmd *cobra.Command, args []string) {
+			err := func() error {
+				app := application.NewApp()
+				if err := app.LoadConfig(cmd); err != nil {
+					return err
+				}
+				if err := app.SetupLogging(); err != nil {
+					return err
+				}
+				version.LogVersion()
+
+				if (honeycombURL == "") == (specFile == "") {
+					return errors.New("Exactly one of --url and --filename must be set")
+				}
+				if app.Config.GetBaseURL() == "" {
+					return errors.New("baseURL must be specified either in config.yaml or via the --base-url flag")
+				}
+
+				opts := convert.HoneycombOptions{
+					BaseURL:       app.Config.GetBaseURL(),
+					Target:        target,
+					ColumnMapping: convert.MergeMappings(app.Config.GetHoneycombColumnMapping(), columnMapping),
+				}
+
+				var link any
+				var warnings []convert.Warning
+				var err error
+				if honeycombURL != "" {
+					link, warnings, err = convert.HoneycombURLToLink(honeycombURL, opts)
+				} else {
+					var raw []byte
+					if specFile == "-" {
+						raw, err = io.ReadAll(os.Stdin)
+					} else {
+						raw, err = os.ReadFile(specFile)
+					}
+					if err != nil {
+						return errors.Wrapf(err, "Error reading query specification %v", specFile)
+					}
+					q := &convert.HoneycombQuery{}
+					if err := json.Unmarshal(raw, q); err != nil {
+						return errors.Wrapf(err, "Error parsing query specification %v", specFile)
+					}
+					link, warnings, err = convert.HoneycombQueryToLink(q, opts)
+				}
+				if err != nil {
+					return err
+				}
+
+				return flags.writeConverted([]convertedLink{{link: link, warnings: warnings}})
+			}()
+
+			if err != nil {
+				fmt.Printf("Error running request;\n %+v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(&honeycombURL, "url", "u", "", "The Honeycomb query URL to convert")
+	cmd.Flags().StringVarP(&specFile, "filename", "f", "", "A JSON file containing the Honeycomb query specification. Use - to read from stdin.")
+	cmd.Flags().StringVarP(&target, "target", "", convert.TargetTraces, "The kind of link to create; traces for a DatadogTraceSearch or logs for a DatadogLink")
+	cmd.Flags().StringToStringVarP(&columnMapping, "column-map", "", nil, "Map a Honeycomb column to a Datadog field e.g. app.tenant=@tenant. Extends the honeycomb.columnMapping config.")
+	flags.addFlags(cmd)
+	return cmd
+}
+
+// NewConvertSplunkCmd creates a command to convert Splunk searches
+func NewConvertSplunkCmd() *cobra.Command {
+	var fieldMapping map[string]string
+	flags := &convertFlags{}
+	cmd := &cobra.Command{
+		Use:   "splunk [search]",
+		Short: "Convert Splunk searches (SPL) into DatadogLinks",
+		Long:  "Convert Splunk searches (SPL) into DatadogLinks. If no search is given it is read from stdin.",
+		Example: `ddctl convert splunk 'index=main sourcetype=nginx status>=500 earliest=-24h | stats count by host'
+ddctl convert splunk --field-map app=service --to url < search.spl`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			err := func() error {
+				app := application.NewApp()
+				if err := app.LoadConfig(cmd); err != nil {
+					return err
+				}
+				if err := app.SetupLogging(); err != nil {
+					return err
+				}
+				version.LogVersion()
+
+				if app.Config.GetBaseURL() == "" {
+					return errors.New("baseURL must be specified either in config.yaml or via the --base-url flag")
+				}
+
+				var spl string
+				if len(args) == 1 {
+					spl = args[0]
+				} else {
+					raw, err := io.ReadAll(os.Stdin)
+					if err != nil {
+						return errors.Wrapf(err, "Error reading search from stdin")
+					}
+					spl = strings.TrimSpace(string(raw))
+				}
+
+				opts := convert.SplunkOptions{
+					BaseURL:      app.Config.GetBaseURL(),
+					FieldMapping: convert.MergeMappings(app.Config.GetSplunkFieldMapping(), fieldMapping),
+				}
+				link, warnings, err := convert.SplunkToLink(spl, opts)
+				if err != nil {
+					return err
+				}
+
+				return flags.writeConverted([]convertedLink{{link: link, warnings: warnings}})
+			}()
+
+			if err != nil {
+				fmt.Printf("Error running request;\n %+v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().StringToStringVarP(&fieldMapping, "field-map", "", nil, "Map a Splunk field to a Datadog field e.g. app=service. Extends the splunk.fieldMapping config.")
+	flags.addFlags(cmd)
+	return cmd
+}
+
+// NewConvertKibanaCmd creates a command to convert Kibana Discover links
+func NewConvertKibanaCmd() *cobra.Command {
+	var kibanaURL string
+	var urlsFile string
+	var fieldMapping map[string]string
+	flags := &convertFlags{}
+	cmd := &cobra.Command{
+		Use:   "kibana",
+		Short: "Convert Kibana Discover links with KQL or Lucene queries into DatadogLinks",
+		Example: `ddctl convert kibana --url=${KIBANA_URL}
+ddctl convert kibana -f bookmarks.txt --field-map labels.team=team`,
+		Run: func(cmd *cobra.Command, args []string) {
+			err := func() error {
+				app := application.NewApp()
+				if err := app.LoadConfig(cmd); err != nil {
+					return err
+				}
+				if err := app.SetupLogging(); err != nil {
+					return err
+				}
+				version.LogVersion()
+
+				if (kibanaURL == "") == (urlsFile == "") {
+					return errors.New("Exactly one of --url and --filename must be set")
+				}
+				if app.Config.GetBaseURL() == "" {
+					return errors.New("baseURL must be specified either in config.yaml or via the --base-url flag")
+				}
+
+				opts := convert.KibanaOptions{
+					BaseURL:      app.Config.GetBaseURL(),
+					FieldMapping: convert.MergeMappings(app.Config.GetKibanaFieldMapping(), fieldMapping),
+				}
+
+				urls := []string{kibanaURL}
+				if urlsFile != "" {
+					raw, err := os.ReadFile(urlsFile)
+					if err != nil {
+						return errors.Wrapf(err, "Error reading file %v", urlsFile)
+					}
+					urls = nil
+					for _, line := range strings.Split(string(raw), "\n") {
+						line = strings.TrimSpace(line)
+						if line == "" || strings.HasPrefix(line, "#") {
+							continue
+						}
+						urls = append(urls, line)
+					}
+				}
+
+				links := make([]convertedLink, 0, len(urls))
+				for _, u := range urls {
+					link, warnings, err := convert.KibanaURLToLink(u, opts)
+					if err != nil {
+						return errors.Wrapf(err, "Failed to convert %v", u)
+					}
+					links = append(links, convertedLink{link: link, warnings: warnings})
+				}
+
+				return flags.writeConverted(links)
+			}()
+
+			if err != nil {
+				fmt.Printf("Error running request;\n %+v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(&kibanaURL, "url", "u", "", "The Kibana Discover URL to convert")
+	cmd.Flags().StringVarP(&urlsFile, "filename", "f", "", "A file containing Kibana Discover URLs to convert; one per line. Lines starting with # are ignored.")
+	cmd.Flags().StringToStringVarP(&fieldMapping, "field-map", "", nil, "Map an Elasticsearch field to a Datadog field e.g. labels.team=team. Extends the kibana.fieldMapping config.")
+	flags.addFlags(cmd)
+	return cmd
+}
+
+// NewConvertGCPCmd creates a command to convert Cloud Logging filters
+func NewConvertGCPCmd() *cobra.Command {
+	var filter string
+	var filterFile string
+	var fieldMapping map[string]string
+	flags := &convertFlags{}
+	cmd := &cobra.Command{
+		Use:   "gcp",
+		Short: "Convert Google Cloud Logging filters into DatadogLinks",
+		Example: `ddctl convert gcp --filter='resource.type="k8s_container" severity>=ERROR'
+ddctl convert gcp -f filter.txt --field-map labels.app=service`,
+		Run: func(cmd *cobra.Command, args []string) {
+			err := func() error {
+				app := application.NewApp()
+				if err := app.LoadConfig(cmd); err != nil {
+					return err
+				}
+				if err := app.SetupLogging(); err != nil {
+					return err
+				}
+				version.LogVersion()
+
+				if (filter == "") == (filterFile == "") {
+					return errors.New("Exactly one of --filter and --filename must be set")
+				}
+				if app.Config.GetBaseURL() == "" {
+					return errors.New("baseURL must be specified either in config.yaml or via the --base-url flag")
+				}
+
+				if filterFile != "" {
+					var raw []byte
+					var err error
+					if filterFile == "-" {
+						raw, err = io.ReadAll(os.Stdin)
+					} else {
+						raw, err = os.ReadFile(filterFile)
+					}
+					if err != nil {
+						return errors.Wrapf(err, "Error reading filter %v", filterFile)
+					}
+					filter = string(raw)
+				}
+
+				opts := convert.GCPOptions{
+					BaseURL:      app.Config.GetBaseURL(),
+					FieldMapping: convert.MergeMappings(app.Config.GetGCPFieldMapping(), fieldMapping),
+				}
+				link, warnings, err := convert.GCPFilterToLink(filter, opts)
+				if err != nil {
+					return err
+				}
+
+				return flags.writeConverted([]convertedLink{{link: link, warnings: warnings}})
+			}()
+
+			if err != nil {
+				fmt.Printf("Error running request;\n %+v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(&filter, "filter", "", "", "The Cloud Logging filter to convert")
+	cmd.Flags().StringVarP(&filterFile, "filename", "f", "", "A file containing the Cloud Logging filter. Use - to read from stdin.")
+	cmd.Flags().StringToStringVarP(&fieldMapping, "field-map", "", nil, "Map a Cloud Logging field to a Datadog field e.g. labels.app=service. Extends the gcp.fieldMapping config.")
+	flags.addFlags(cmd)
+	return cmd
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/jlewi/ddctl/pkg/ddog"
+	"github.com/jlewi/monogo/yamlfiles"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// NewDiffCmd creates a command to show the field level differences between two links
+func NewDiffCmd(w io.Writer) *cobra.Command {
+	var output string
+	cmd := &cobra.Command{
+		Use:   "diff <a> <b>",
+		Short: "Show the field level differences between two links given as URLs or YAML files. Like diff(1) it exits with status 1 if the links differ and 2 if there was an error.",
+		Example: `ddctl links diff "${URL_A}" "${URL_B}"
+ddctl links diff before.yaml after.yaml --output json`,
+		Args: cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			diff, err := func() (*ddog.LinkDiff, error) {
+				a, err := readLinkArg(args[0])
+				if err != nil {
+					return nil, err
+				}
+				b, err := readLinkArg(args[1])
+				if err != nil {
+					return nil, err
+				}
+
+				diff, err := ddog.DiffLinks(a, b)
+				if err != nil {
+					return nil, err
+				}
+
+				switch output {
+				case "text":
+					writeLinkDiff(w, diff)
+				case "json":
+					encoder := json.NewEncoder(w)
+					encoder.SetIndent("", "  ")
+					if err := encoder.Encode(diff); err != nil {
+						return nil, errors.Wrapf(err, "Error writing diff")
+					}
+				default:
+					return nil, errors.Errorf("Unsupported output %v; must be text or json", output)
+				}
+				return diff, nil
+			}()
+
+			if err != nil {
+				fmt.Printf("Error running request;\n %+v\n", err)
+				os.Exit(2)
+			}
+			if !diff.Empty() {
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "", "text", "The output format; text or json")
+	return cmd
+}
+
+// readLinkArg reads a link given as a URL or a YAML file containing a single link.
+func readLinkArg(arg string) (any, error) {
+	if strings.HasPrefix(arg, "http://") || strings.HasPrefix(arg, "https://") {
+		link, err := ddog.URLToLink(arg)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error parsing URL %v", arg)
+		}
+		return link, nil
+	}
+
+	nodes, err := yamlfiles.Read(arg)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error reading file %v", arg)
+	}
+	var links []any
+	for _, n := range nodes {
+		link, err := decodeLink(n)
+		if err != nil {
+			return nil, err
+		}
+		if link != nil {
+			links = append(links, link)
+		}
+	}
+	if len(links) != 1 {
+		return nil, errors.Errorf("%v must contain exactly one link but it contains %v; known kinds are %v", arg, len(links), knownKinds)
+	}
+	return links[0], nil
+}
+
+// writeLinkDiff writes a diff with one change per line.
+func writeLinkDiff(w io.Writer, diff *ddog.LinkDiff) {
+	if diff.Empty() {
+		fmt.Fprintln(w, "No differences")
+		return
+	}
+	if diff.TimeWindow != nil {
+		fmt.Fprintln(w, diff.TimeWindow.String())
+	}
+	for _, c := range diff.Changes {
+		fmt.Fprintln(w, c.String())
+	}
+}
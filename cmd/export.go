@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/go-logr/zapr"
+	"github.com/jlewi/ddctl/api"
+	"github.com/jlewi/ddctl/pkg/application"
+	"github.com/jlewi/ddctl/pkg/convert"
+	"github.com/jlewi/ddctl/pkg/ddog"
+	"github.com/jlewi/grafctl/pkg/grafana"
+	"github.com/jlewi/monogo/yamlfiles"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+const (
+	exportGCP     = "gcp"
+	exportGrafana = "grafana"
+)
+
+// NewExportCmd creates a command to export Datadog links to other tools
+func NewExportCmd() *cobra.Command {
+	var linksFile string
+	var linkURL string
+	var outFile string
+	var to string
+	var fieldMapping map[string]string
+	var grafanaURL string
+	var datasource string
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export DatadogLinks as the equivalent queries in other tools",
+		Example: `ddctl links export --to gcp --url=${URL}
+ddctl links export --to gcp -f links.yaml --field-map labels.app=service
+ddctl links export --to grafana --url=${URL} --grafana-url=https://grafana.acme.com --datasource=${LOKI_UID}
+ddctl links export --to grafana -f links.yaml --field-map service=app`,
+		Run: func(cmd *cobra.Command, args []string) {
+			err := func() error {
+				app := application.NewApp()
+				if err := app.LoadConfig(cmd); err != nil {
+					return err
+				}
+				if err := app.SetupLogging(); err != nil {
+					return err
+				}
+
+				if (linksFile == "") == (linkURL == "") {
+					return errors.New("Exactly one of --url and --filename must be set")
+				}
+
+				var links []*api.DatadogLink
+				if linkURL != "" {
+					link, err := ddog.URLToLink(linkURL)
+					if err != nil {
+						return errors.Wrapf(err, "Error parsing URL")
+					}
+					dl, ok := link.(*api.DatadogLink)
+					if !ok {
+						return errors.Errorf("Only %v links can be exported; the URL is a %T", api.LinkGVK.Kind, link)
+					}
+					links = append(links, dl)
+				} else {
+					nodes, err := yamlfiles.Read(linksFile)
+					if err != nil {
+						return errors.Wrapf(err, "Error reading file %v", linksFile)
+					}
+					for _, n := range nodes {
+						if n.GetKind() != api.LinkGVK.Kind {
+							log := zapr.NewLogger(zap.L())
+							log.Info("Skipping resource; only DatadogLinks can be exported", "kind", n.GetKind(), "name", n.GetName())
+							continue
+						}
+						link, err := decodeLink(n)
+						if err != nil {
+							return err
+						}
+						links = append(links, link.(*api.DatadogLink))
+					}
+				}
+
+				var w io.Writer = os.Stdout
+				if outFile != "" {
+					f, err := os.Create(outFile)
+					if err != nil {
+						return errors.Wrapf(err, "Error creating file %v", outFile)
+					}
+					defer f.Close()
+					w = f
+				}
+
+				switch to {
+				case exportGCP:
+					opts := convert.GCPOptions{
+						FieldMapping: convert.MergeMappings(app.Config.GetGCPFieldMapping(), fieldMapping),
+					}
+					return exportGCPFilters(w, links, opts)
+				case exportGrafana:
+					opts := convert.GrafanaExportOptions{
+						BaseURL:    app.Config.GetGrafanaBaseURL(),
+						Datasource: app.Config.GetGrafanaDatasource(),
+						// Tags map to the labels they are converted from unless a tag mapping overrides them
+						TagMapping: convert.MergeMappings(convert.InvertMapping(app.Config.GetGrafanaLabelMapping()), app.Config.GetGrafanaTagMapping(), fieldMapping),
+					}
+					if grafanaURL != "" {
+						opts.BaseURL = strings.TrimSuffix(grafanaURL, "/")
+					}
+					if datasource != "" {
+						opts.Datasource = datasource
+					}
+					if opts.BaseURL == "" {
+						return errors.New("The Grafana URL must be specified either in config.yaml as grafana.baseURL or via the --grafana-url flag")
+					}
+					return exportGrafanaLinks(w, links, opts)
+				default:
+					return errors.Errorf("Unsupported target %v; must be %v or %v", to, exportGCP, exportGrafana)
+				}
+			}()
+
+			if err != nil {
+				fmt.Printf("Error running request;\n %+v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(&linksFile, "filename", "f", "", "A YAML file containing the DatadogLinks to export")
+	cmd.Flags().StringVarP(&linkURL, "url", "u", "", "The URL of the Datadog link to export")
+	cmd.Flags().StringVarP(&outFile, "output-file", "o", "", "File to write the result to. If not specified the result is written to stdout.")
+	cmd.Flags().StringVarP(&to, "to", "", exportGCP, "The tool to export to; gcp writes Cloud Logging filters and grafana writes Grafana Explore URLs with LogQL queries")
+	cmd.Flags().StringToStringVarP(&fieldMapping, "field-map", "", nil, "Map fields between Datadog and the target tool. For gcp map a Cloud Logging field to a Datadog field e.g. labels.app=service. For grafana map a Datadog tag to a Loki label e.g. service=app. Extends the mapping in the config for the tool.")
+	cmd.Flags().StringVarP(&grafanaURL, "grafana-url", "", "", "The base URL of Grafana e.g. https://grafana.acme.com. Overrides grafana.baseURL in the config.")
+	cmd.Flags().StringVarP(&datasource, "datasource", "", "", "The UID of the Loki datasource in Grafana. Overrides grafana.datasource in the config.")
+	return cmd
+}
+
+// exportGCPFilters writes the Cloud Logging filter for each link. Filters are separated by a blank line and
+// the name of the link and any warnings are written as -- comments so the output can be pasted into the console.
+func exportGCPFilters(w io.Writer, links []*api.DatadogLink, opts convert.GCPOptions) error {
+	for i, link := range links {
+		filter, warnings, err := convert.LinkToGCPFilter(link, opts)
+		if err != nil {
+			return errors.Wrapf(err, "Failed to export %v", link.Metadata.Name)
+		}
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		if link.Metadata.Name != "" {
+			fmt.Fprintf(w, "-- %v\n", link.Metadata.Name)
+		}
+		for _, warning := range warnings {
+			fmt.Fprintf(w, "-- Warning: %v\n", warning)
+		}
+		fmt.Fprintln(w, filter)
+	}
+	return nil
+}
+
+// exportGrafanaLinks writes the Grafana Explore URL for each link. Warnings are written to stderr.
+func exportGrafanaLinks(w io.Writer, links []*api.DatadogLink, opts convert.GrafanaExportOptions) error {
+	for _, link := range links {
+		glink, warnings, err := convert.LinkToGrafanaLink(link, opts)
+		if err != nil {
+			return errors.Wrapf(err, "Failed to export %v", link.Metadata.Name)
+		}
+		for _, warning := range warnings {
+			fmt.Fprintf(os.Stderr, "Warning: %v: %v\n", link.Metadata.Name, warning)
+		}
+		u, err := grafana.LinkToURL(*glink)
+		if err != nil {
+			return errors.Wrapf(err, "Failed to build the Grafana URL for %v", link.Metadata.Name)
+		}
+		fmt.Fprintln(w, u)
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"github.com/jlewi/ddctl/pkg/ddog"
 	"io"
@@ -10,6 +11,7 @@ import (
 	"github.com/go-logr/zapr"
 	"github.com/jlewi/monogo/yamlfiles"
 	"go.uber.org/zap"
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
 	yaml "sigs.k8s.io/yaml/goyaml.v3"
 
 	"github.com/jlewi/monogo/helpers"
@@ -22,12 +24,27 @@ import (
 	"github.com/spf13/cobra"
 )
 
+const (
+	// apiKeyEnvVar and appKeyEnvVar are the environment variables containing the credentials for the Datadog API.
+	apiKeyEnvVar = "DD_API_KEY"
+	appKeyEnvVar = "DD_APP_KEY"
+)
+
+var (
+	// knownKinds is the list of kinds that links build knows how to handle.
+	knownKinds = []string{api.LinkGVK.Kind, api.TraceGVK.Kind, api.ErrorTrackingGVK.Kind, api.DatabaseGVK.Kind, api.CIGVK.Kind, api.SecuritySignalGVK.Kind, api.LLMTraceGVK.Kind, api.ServerlessGVK.Kind, api.NetworkGVK.Kind, api.AuditGVK.Kind, api.CatalogEntityGVK.Kind, api.TraceSearchGVK.Kind}
+)
+
 func NewLinksCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use: "links",
 	}
 	cmd.AddCommand(NewBuildURL())
 	cmd.AddCommand(NewParseURL())
+	cmd.AddCommand(NewEditQueryCmd())
+	cmd.AddCommand(NewExportCmd())
+	cmd.AddCommand(NewDiffCmd(os.Stdout))
+	cmd.AddCommand(NewTraceCmd(os.Stdout))
 	return cmd
 }
 
@@ -35,6 +52,7 @@ func NewLinksCmd() *cobra.Command {
 func NewBuildURL() *cobra.Command {
 	var patchFile string
 	var open bool
+	var formatQueries bool
 	cmd := &cobra.Command{
 		Use: "build",
 		Run: func(cmd *cobra.Command, args []string) {
@@ -56,28 +74,23 @@ func NewBuildURL() *cobra.Command {
 
 				log := zapr.NewLogger(zap.L())
 				for _, n := range nodes {
-					var u string
-					switch n.GetKind() {
-					case api.LinkGVK.Kind:
-						link := &api.DatadogLink{}
-						if err := n.YNode().Decode(link); err != nil {
-							return errors.Wrapf(err, "Error decoding %v", n.GetKind())
-						}
-						u, err = ddog.BuildURL(link)
-						if err != nil {
-							return err
-						}
-					case api.TraceGVK.Kind:
-						link := &api.DatadogTrace{}
-						if err := n.YNode().Decode(link); err != nil {
-							return errors.Wrapf(err, "Error decoding %v", n.GetKind())
-						}
-						u, err = ddog.BuildTraceURL(link)
-						if err != nil {
-							return err
-						}
-					default:
-						log.Info("Skipping object of unknown kind", "kind", n.GetKind(), "name", n.GetName(), "knownKinds", []string{api.LinkGVK.Kind})
+					link, err := decodeLink(n)
+					if err != nil {
+						return err
+					}
+					if link == nil {
+						log.Info("Skipping object of unknown kind", "kind", n.GetKind(), "name", n.GetName(), "knownKinds", knownKinds)
+						continue
+					}
+
+					if err := formatLinkQueries(link, formatQueries); err != nil {
+						return err
+					}
+					warnLinkFindings(link, n.GetName())
+
+					u, err := ddog.LinkToURL(link)
+					if err != nil {
+						return err
 					}
 
 					fmt.Printf("Datadog URL:\n%v\n", u)
@@ -100,6 +113,7 @@ func NewBuildURL() *cobra.Command {
 
 	cmd.Flags().StringVarP(&patchFile, "--filename", "f", "", "A file containing the YAML object containing the link.")
 	cmd.Flags().BoolVarP(&open, "open", "", false, "Open the URL in a browser")
+	cmd.Flags().BoolVarP(&formatQueries, "fmt", "", false, "Format the queries in the links before building the URLs")
 	return cmd
 }
 
@@ -108,6 +122,8 @@ func NewParseURL() *cobra.Command {
 	var panesFile string
 	var logUrl string
 	var name string
+	var resolveSavedView bool
+	var formatQueries bool
 	cmd := &cobra.Command{
 		Use: "parse",
 		Run: func(cmd *cobra.Command, args []string) {
@@ -144,15 +160,31 @@ func NewParseURL() *cobra.Command {
 					o = os.Stdout
 				}
 
-				linkAny, err := ddog.URLToLink(logUrl)
+				link, err := ddog.URLToLink(logUrl)
 				if err != nil {
 					return errors.Wrapf(err, "Error parsing URL")
 				}
-				link, ok := linkAny.(*api.DatadogLink)
-				if !ok {
-					return errors.Errorf("Expected a DatadogLink but got %T", link)
+				if err := setLinkName(link, name); err != nil {
+					return err
+				}
+
+				if resolveSavedView {
+					logsLink, ok := link.(*api.DatadogLink)
+					if !ok {
+						return errors.Errorf("--resolve-saved-view is only supported for %v but got %T", api.LinkGVK.Kind, link)
+					}
+					client, err := ddog.NewSavedViewClient(app.Config.GetAPIURL(), os.Getenv(apiKeyEnvVar), os.Getenv(appKeyEnvVar))
+					if err != nil {
+						return errors.Wrapf(err, "Failed to create client; set the environment variables %v and %v", apiKeyEnvVar, appKeyEnvVar)
+					}
+					if err := client.ResolveSavedView(context.Background(), logsLink); err != nil {
+						return err
+					}
+				}
+
+				if err := formatLinkQueries(link, formatQueries); err != nil {
+					return err
 				}
-				link.Metadata.Name = name
 
 				// Pretty print the json of the panes to the file
 				encoder := yaml.NewEncoder(o)
@@ -173,6 +205,201 @@ func NewParseURL() *cobra.Command {
 	cmd.Flags().StringVarP(&panesFile, "link-file", "o", "", "File to write the yaml to. If not specified the Link will be written to stdout.")
 	cmd.Flags().StringVarP(&name, "name", "n", "", "Name to give the resource when saving to a file")
 	cmd.Flags().StringVarP(&logUrl, "url", "u", "", "The URL to parse")
+	cmd.Flags().BoolVarP(&formatQueries, "fmt", "", false, "Format the queries in the link")
+	cmd.Flags().BoolVarP(&resolveSavedView, "resolve-saved-view", "", false, "Inline the query, columns and facets of the saved view referenced by the URL so the link doesn't depend on it. Requires the DD_API_KEY and DD_APP_KEY environment variables.")
 	helpers.IgnoreError(cmd.MarkFlagRequired("url"))
 	return cmd
 }
+
+// NewEditQueryCmd creates a command to add, remove or replace terms in the query of a link
+func NewEditQueryCmd() *cobra.Command {
+	var linksFile string
+	var linkURL string
+	var outFile string
+	var to string
+	var field string
+	edit := ddog.QueryEdit{}
+	cmd := &cobra.Command{
+		Use:   "edit-query",
+		Short: "Add, remove or replace terms in the query of a link given as a URL or YAML file",
+		Example: `ddctl links edit-query --url=${URL} --add env:staging --remove 'service:feserver*' --replace @http.method:POST
+ddctl links edit-query -f link.yaml --add -status:info -o link.yaml`,
+		Run: func(cmd *cobra.Command, args []string) {
+			err := func() error {
+				app := application.NewApp()
+				if err := app.LoadConfig(cmd); err != nil {
+					return err
+				}
+				if err := app.SetupLogging(); err != nil {
+					return err
+				}
+
+				if (linksFile == "") == (linkURL == "") {
+					return errors.New("Exactly one of --url and --filename must be set")
+				}
+
+				var links []any
+				if linkURL != "" {
+					link, err := ddog.URLToLink(linkURL)
+					if err != nil {
+						return errors.Wrapf(err, "Error parsing URL")
+					}
+					links = append(links, link)
+					if to == "" {
+						to = "url"
+					}
+				} else {
+					nodes, err := yamlfiles.Read(linksFile)
+					if err != nil {
+						return errors.Wrapf(err, "Error reading file %v", linksFile)
+					}
+					for _, n := range nodes {
+						link, err := decodeLink(n)
+						if err != nil {
+							return err
+						}
+						if link == nil {
+							return errors.Errorf("%v %v isn't a link; known kinds are %v", n.GetKind(), n.GetName(), knownKinds)
+						}
+						links = append(links, link)
+					}
+					if to == "" {
+						to = "yaml"
+					}
+				}
+
+				for _, link := range links {
+					if err := ddog.EditLinkQuery(link, field, edit); err != nil {
+						return queryError(errors.Cause(err))
+					}
+				}
+
+				o := os.Stdout
+				if outFile != "" {
+					f, err := os.Create(outFile)
+					if err != nil {
+						return errors.Wrapf(err, "Error creating file %v", outFile)
+					}
+					defer f.Close()
+					o = f
+				}
+
+				switch to {
+				case "url":
+					for _, link := range links {
+						u, err := ddog.LinkToURL(link)
+						if err != nil {
+							return err
+						}
+						fmt.Fprintln(o, u)
+					}
+				case "yaml":
+					encoder := yaml.NewEncoder(o)
+					encoder.SetIndent(2)
+					for _, link := range links {
+						if err := encoder.Encode(link); err != nil {
+							return errors.Wrapf(err, "Error writing Link")
+						}
+					}
+				default:
+					return errors.Errorf("Unsupported output %v; must be url or yaml", to)
+				}
+				return nil
+			}()
+
+			if err != nil {
+				fmt.Printf("Error running request;\n %+v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(&linksFile, "filename", "f", "", "A YAML file containing the links to edit")
+	cmd.Flags().StringVarP(&linkURL, "url", "u", "", "The URL of the link to edit")
+	cmd.Flags().StringVarP(&outFile, "output-file", "o", "", "File to write the result to. If not specified the result is written to stdout.")
+	cmd.Flags().StringVarP(&to, "to", "", "", "Write the edited links as url or yaml. Defaults to the format of the input.")
+	cmd.Flags().StringVarP(&field, "field", "", "query", "The query field to edit e.g. sourceFilter for DatadogNetwork links")
+	cmd.Flags().StringArrayVarP(&edit.Add, "add", "", nil, "A term to AND with the query e.g. env:staging. Can be repeated.")
+	cmd.Flags().StringArrayVarP(&edit.Remove, "remove", "", nil, "A term to remove from the query e.g. service:feserver*. Prefix with - to remove a negated term. Can be repeated.")
+	cmd.Flags().StringArrayVarP(&edit.Replace, "replace", "", nil, "A field whose value replaces the current value of the field e.g. @http.method:POST. Can be repeated.")
+	return cmd
+}
+
+// decodeLink decodes a YAML object into the link resource for its kind. It returns nil if the kind isn't a link.
+func decodeLink(n *kyaml.RNode) (any, error) {
+	var link any
+	switch n.GetKind() {
+	case api.LinkGVK.Kind:
+		link = &api.DatadogLink{}
+	case api.TraceGVK.Kind:
+		link = &api.DatadogTrace{}
+	case api.ErrorTrackingGVK.Kind:
+		link = &api.DatadogErrorTracking{}
+	case api.DatabaseGVK.Kind:
+		link = &api.DatadogDatabase{}
+	case api.CIGVK.Kind:
+		link = &api.DatadogCI{}
+	case api.SecuritySignalGVK.Kind:
+		link = &api.DatadogSecuritySignal{}
+	case api.LLMTraceGVK.Kind:
+		link = &api.DatadogLLMTrace{}
+	case api.ServerlessGVK.Kind:
+		link = &api.DatadogServerless{}
+	case api.NetworkGVK.Kind:
+		link = &api.DatadogNetwork{}
+	case api.AuditGVK.Kind:
+		link = &api.DatadogAudit{}
+	case api.CatalogEntityGVK.Kind:
+		link = &api.DatadogCatalogEntity{}
+	case api.TraceSearchGVK.Kind:
+		link = &api.DatadogTraceSearch{}
+	default:
+		return nil, nil
+	}
+
+	if err := n.YNode().Decode(link); err != nil {
+		return nil, errors.Wrapf(err, "Error decoding %v", n.GetKind())
+	}
+	return link, nil
+}
+
+// setLinkName sets the name in the metadata of a link returned by ddog.URLToLink.
+func setLinkName(link any, name string) error {
+	switch v := link.(type) {
+	case *api.DatadogLink:
+		v.Metadata.Name = name
+	case *api.DatadogTrace:
+		v.Metadata.Name = name
+	case *api.DatadogErrorTracking:
+		v.Metadata.Name = name
+	case *api.DatadogDatabase:
+		v.Metadata.Name = name
+	case *api.DatadogCI:
+		v.Metadata.Name = name
+	case *api.DatadogSecuritySignal:
+		v.Metadata.Name = name
+	case *api.DatadogLLMTrace:
+		v.Metadata.Name = name
+	case *api.DatadogServerless:
+		v.Metadata.Name = name
+	case *api.DatadogNetwork:
+		v.Metadata.Name = name
+	case *api.DatadogAudit:
+		v.Metadata.Name = name
+	case *api.DatadogCatalogEntity:
+		v.Metadata.Name = name
+	case *api.DatadogTraceSearch:
+		v.Metadata.Name = name
+	default:
+		return errors.Errorf("Unsupported link type %T", link)
+	}
+	return nil
+}
+
+// formatLinkQueries formats the queries in the link if enabled is true.
+func formatLinkQueries(link any, enabled bool) error {
+	if !enabled {
+		return nil
+	}
+	return queryError(ddog.FormatLinkQueries(link))
+}
@@ -0,0 +1,247 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/jlewi/ddctl/pkg/ddog"
+	"github.com/jlewi/ddctl/pkg/query"
+	"github.com/jlewi/monogo/yamlfiles"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// NewQueryCmd adds commands to work with Datadog search queries
+func NewQueryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "query",
+		Short: "Work with Datadog log search queries",
+	}
+
+	cmd.AddCommand(NewFormatQueryCmd(os.Stdout))
+	cmd.AddCommand(NewLintQueryCmd(os.Stdout))
+	return cmd
+}
+
+// NewFormatQueryCmd creates a command to print queries in their canonical form
+func NewFormatQueryCmd(w io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "fmt [query...]",
+		Short: "Print queries in their canonical form. If no query is given the query is read from stdin.",
+		Example: `ddctl query fmt 'service:foo AND NOT status:info'
+ddctl query fmt '-status:info service:foo'
+echo 'env:(prod) @http.method:"GET"' | ddctl query fmt`,
+		// Queries often start with "-" e.g. -status:info so the flags are parsed by parseQueryArgs.
+		DisableFlagParsing: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			err := func() error {
+				args, err := parseQueryArgs(cmd, args)
+				if err != nil {
+					return err
+				}
+				if args == nil {
+					return cmd.Help()
+				}
+				queries, err := queriesFromArgs(args)
+				if err != nil {
+					return err
+				}
+
+				for _, q := range queries {
+					formatted, err := query.Format(q)
+					if err != nil {
+						return queryError(err)
+					}
+					fmt.Fprintln(w, formatted)
+				}
+				return nil
+			}()
+
+			if err != nil {
+				fmt.Printf("Failed to format query;\n%v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	return cmd
+}
+
+// parseQueryArgs parses the flags of a command with DisableFlagParsing set and returns the remaining arguments.
+// Arguments that aren't flags of the command are returned as is so a query such as -status:info isn't rejected
+// as an unknown flag. Arguments after "--" are never parsed as flags. It returns nil if help was requested.
+func parseQueryArgs(cmd *cobra.Command, args []string) ([]string, error) {
+	flags := cmd.Flags()
+	flagArgs := make([]string, 0, len(args))
+	remaining := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			remaining = append(remaining, args[i+1:]...)
+			break
+		}
+
+		var f *pflag.Flag
+		if name, ok := strings.CutPrefix(arg, "--"); ok {
+			name, _, _ = strings.Cut(name, "=")
+			f = flags.Lookup(name)
+		} else if name, ok := strings.CutPrefix(arg, "-"); ok {
+			name, _, _ = strings.Cut(name, "=")
+			if len(name) == 1 {
+				f = flags.ShorthandLookup(name)
+			}
+		}
+		if f == nil {
+			remaining = append(remaining, arg)
+			continue
+		}
+
+		flagArgs = append(flagArgs, arg)
+		// The value of a flag that isn't a boolean is the next argument unless it is set with "=".
+		if f.NoOptDefVal == "" && !strings.Contains(arg, "=") && i+1 < len(args) {
+			i++
+			flagArgs = append(flagArgs, args[i])
+		}
+	}
+
+	if err := flags.Parse(flagArgs); err != nil {
+		return nil, errors.Wrapf(err, "Failed to parse flags")
+	}
+	if help, err := flags.GetBool("help"); err == nil && help {
+		return nil, nil
+	}
+	return remaining, nil
+}
+
+// queriesFromArgs returns the queries passed as arguments or, if there are none, the query read from stdin.
+func queriesFromArgs(args []string) ([]string, error) {
+	if len(args) > 0 {
+		return args, nil
+	}
+
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to read the query from stdin")
+	}
+	return []string{strings.TrimSpace(string(data))}, nil
+}
+
+// queryError adds the location of a syntax error to the error.
+func queryError(err error) error {
+	var pErr *query.ParseError
+	if errors.As(err, &pErr) {
+		return errors.Errorf("%v\n%v", pErr.Error(), pErr.Context())
+	}
+	return err
+}
+
+// NewLintQueryCmd creates a command to lint queries
+func NewLintQueryCmd(w io.Writer) *cobra.Command {
+	var linksFile string
+	cmd := &cobra.Command{
+		Use:   "lint [query...]",
+		Short: "Report likely mistakes in queries. If no query is given the query is read from stdin. Exits with a non-zero status if there are findings.",
+		Example: `ddctl query lint 'service:web or status:ERROR'
+ddctl query lint '-status:info service:web'
+ddctl query lint -f links.yaml`,
+		// Queries often start with "-" e.g. -status:info so the flags are parsed by parseQueryArgs.
+		DisableFlagParsing: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			numFindings, err := func() (int, error) {
+				args, err := parseQueryArgs(cmd, args)
+				if err != nil {
+					return 0, err
+				}
+				if args == nil {
+					return 0, cmd.Help()
+				}
+				if linksFile != "" {
+					return lintLinksFile(w, linksFile)
+				}
+
+				queries, err := queriesFromArgs(args)
+				if err != nil {
+					return 0, err
+				}
+
+				numFindings := 0
+				for _, q := range queries {
+					findings, err := query.Lint(q)
+					if err != nil {
+						return 0, queryError(err)
+					}
+					for _, f := range findings {
+						fmt.Fprintln(w, formatFinding("query", q, f))
+					}
+					numFindings += len(findings)
+				}
+				return numFindings, nil
+			}()
+
+			if err != nil {
+				fmt.Printf("Failed to lint query;\n%v\n", err)
+				os.Exit(1)
+			}
+			if numFindings > 0 {
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(&linksFile, "filename", "f", "", "A YAML file containing links whose queries should be linted")
+	return cmd
+}
+
+// lintLinksFile lints the queries of the links in the file and returns the number of findings.
+func lintLinksFile(w io.Writer, path string) (int, error) {
+	nodes, err := yamlfiles.Read(path)
+	if err != nil {
+		return 0, errors.Wrapf(err, "Error reading file %v", path)
+	}
+
+	numFindings := 0
+	for _, n := range nodes {
+		link, err := decodeLink(n)
+		if err != nil {
+			return 0, err
+		}
+		if link == nil {
+			continue
+		}
+
+		findings, err := ddog.LintLink(link)
+		if err != nil {
+			return 0, errors.Wrapf(queryError(errors.Cause(err)), "Failed to lint %v %v", n.GetKind(), n.GetName())
+		}
+		for _, f := range findings {
+			fmt.Fprintf(w, "%v: %v\n", n.GetName(), formatFinding(f.Field, f.Query, f.Finding))
+		}
+		numFindings += len(findings)
+	}
+	return numFindings, nil
+}
+
+// warnLinkFindings prints the lint findings for the queries in a link to stderr. Lint doesn't block building
+// the link because the findings may be intentional.
+func warnLinkFindings(link any, name string) {
+	findings, err := ddog.LintLink(link)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v: %v\n", name, err)
+		return
+	}
+	for _, f := range findings {
+		fmt.Fprintf(os.Stderr, "Warning: %v: %v\n", name, formatFinding(f.Field, f.Query, f.Finding))
+	}
+}
+
+// formatFinding returns a single line describing the finding in the field.
+func formatFinding(field string, q string, f query.Finding) string {
+	if !f.HasFix {
+		return fmt.Sprintf("%v:%v: %v [%v]", field, f.Column(q), f.Message, f.Rule)
+	}
+	fix := strings.TrimSpace(f.ApplyFix(q))
+	return fmt.Sprintf("%v:%v: %v [%v]; suggested fix: %v", field, f.Column(q), f.Message, f.Rule, fix)
+}
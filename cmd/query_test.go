@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"io"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_parseQueryArgs(t *testing.T) {
+	type testCase struct {
+		name      string
+		args      []string
+		expected  []string
+		linksFile string
+	}
+
+	cases := []testCase{
+		{
+			name:     "leading-minus",
+			args:     []string{"-status:info service:web"},
+			expected: []string{"-status:info service:web"},
+		},
+		{
+			name:     "leading-minus-field",
+			args:     []string{"-service:web", "-host:a"},
+			expected: []string{"-service:web", "-host:a"},
+		},
+		{
+			name:      "shorthand",
+			args:      []string{"-f", "links.yaml"},
+			expected:  []string{},
+			linksFile: "links.yaml",
+		},
+		{
+			name:      "long-with-value",
+			args:      []string{"--filename=links.yaml", "-status:info"},
+			expected:  []string{"-status:info"},
+			linksFile: "links.yaml",
+		},
+		{
+			name:     "double-dash",
+			args:     []string{"--", "-f"},
+			expected: []string{"-f"},
+		},
+		{
+			name:     "help",
+			args:     []string{"--help"},
+			expected: nil,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cmd := NewLintQueryCmd(io.Discard)
+			cmd.InitDefaultHelpFlag()
+			actual, err := parseQueryArgs(cmd, c.args)
+			if err != nil {
+				t.Fatalf("Failed to parse args; %v", err)
+			}
+			if d := cmp.Diff(c.expected, actual); d != "" {
+				t.Errorf("Unexpected args (-want +got):\n%s", d)
+			}
+			linksFile, err := cmd.Flags().GetString("filename")
+			if err != nil {
+				t.Fatalf("Failed to get filename; %v", err)
+			}
+			if linksFile != c.linksFile {
+				t.Errorf("Got filename %v; want %v", linksFile, c.linksFile)
+			}
+		})
+	}
+}
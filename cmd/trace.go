@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/jlewi/ddctl/api"
+	"github.com/jlewi/ddctl/pkg/application"
+	"github.com/jlewi/ddctl/pkg/config"
+	"github.com/jlewi/ddctl/pkg/ddog"
+	"github.com/jlewi/ddctl/pkg/version"
+	"github.com/pkg/browser"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	yaml "sigs.k8s.io/yaml/goyaml.v3"
+)
+
+// NewTraceCmd creates a command to build a trace link from trace IDs in Datadog, OpenTelemetry or W3C traceparent format
+func NewTraceCmd(w io.Writer) *cobra.Command {
+	var baseURL string
+	var traceparent string
+	var otelTraceID string
+	var traceID string
+	var spanID string
+	var format string
+	var to string
+	var open bool
+	cmd := &cobra.Command{
+		Use:   "trace",
+		Short: "Build a link to an APM trace from a W3C traceparent header, an OpenTelemetry trace_id or a Datadog trace ID",
+		Example: `ddctl links trace --traceparent 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+ddctl links trace --otel-trace-id 4bf92f3577b34da6a3ce929d0e0e4736 --format decimal
+ddctl links trace --trace-id 11803532876627986230 --to ids`,
+		Run: func(cmd *cobra.Command, args []string) {
+			err := func() error {
+				app := application.NewApp()
+				if err := app.LoadConfig(cmd); err != nil {
+					return err
+				}
+				if err := app.SetupLogging(); err != nil {
+					return err
+				}
+				version.LogVersion()
+
+				numSet := 0
+				for _, v := range []string{traceparent, otelTraceID, traceID} {
+					if v != "" {
+						numSet++
+					}
+				}
+				if numSet != 1 {
+					return errors.New("Exactly one of --traceparent, --otel-trace-id and --trace-id must be set")
+				}
+
+				var id ddog.TraceID
+				var err error
+				switch {
+				case traceparent != "":
+					if spanID != "" {
+						return errors.New("--span-id can't be used with --traceparent; the span ID is the parent-id of the header")
+					}
+					tp, tpErr := ddog.ParseTraceparent(traceparent)
+					if tpErr != nil {
+						return tpErr
+					}
+					id = tp.TraceID
+					spanID = tp.SpanID
+				case otelTraceID != "":
+					id, err = ddog.ParseOTelTraceID(otelTraceID)
+				default:
+					id, err = ddog.ParseTraceID(traceID)
+				}
+				if err != nil {
+					return err
+				}
+
+				if spanID != "" {
+					spanID, err = ddog.ParseSpanID(spanID)
+					if err != nil {
+						return err
+					}
+				}
+
+				if to == "ids" {
+					return writeTraceIDs(w, id, spanID)
+				}
+
+				if app.Config.GetBaseURL() == "" {
+					return errors.New("baseURL must be specified either in config.yaml or via the --base-url flag")
+				}
+
+				// Datadog trace URLs identify traces by 128-bit hex or 64-bit decimal IDs
+				if ddog.TraceIDFormat(format) == ddog.TraceIDFormatLower64 {
+					return errors.Errorf("--format %v can only be used with --to ids; trace links use hex or decimal IDs", format)
+				}
+				formatted, err := id.Format(ddog.TraceIDFormat(format))
+				if err != nil {
+					return err
+				}
+
+				link := &api.DatadogTrace{
+					APIVersion: api.TraceGVK.GroupVersion().String(),
+					Kind:       api.TraceGVK.Kind,
+					BaseURL:    app.Config.GetBaseURL(),
+					TraceID:    formatted,
+					SpanID:     spanID,
+				}
+
+				switch to {
+				case "url":
+					u, err := ddog.LinkToURL(link)
+					if err != nil {
+						return err
+					}
+					fmt.Fprintln(w, u)
+					if open {
+						if err := browser.OpenURL(u); err != nil {
+							return errors.Wrapf(err, "Error opening URL %v", u)
+						}
+					}
+				case "yaml":
+					encoder := yaml.NewEncoder(w)
+					encoder.SetIndent(2)
+					if err := encoder.Encode(link); err != nil {
+						return errors.Wrapf(err, "Error writing Link")
+					}
+				default:
+					return errors.Errorf("Unsupported output %v; must be url, yaml or ids", to)
+				}
+				return nil
+			}()
+
+			if err != nil {
+				fmt.Printf("Error running request;\n %+v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(&baseURL, config.BaseURLFlagName, "", "", "The base URL for your Datadog URLs. It should be something like https://acme.datadoghq.com")
+	cmd.Flags().StringVarP(&traceparent, "traceparent", "", "", "A W3C traceparent header e.g. 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	cmd.Flags().StringVarP(&otelTraceID, "otel-trace-id", "", "", "An OpenTelemetry trace_id; 32 hex characters")
+	cmd.Flags().StringVarP(&traceID, "trace-id", "", "", "A trace ID in hex or decimal. Values consisting only of digits are decimal unless they have 32 digits; use a 0x prefix for hex.")
+	cmd.Flags().StringVarP(&spanID, "span-id", "", "", "The span ID to select in hex or decimal. Values consisting only of digits are decimal; use a 0x prefix for hex.")
+	cmd.Flags().StringVarP(&format, "format", "", string(ddog.TraceIDFormatAuto), fmt.Sprintf("The format of the trace ID in the link; one of %v. auto uses hex for 128-bit IDs and decimal for 64-bit IDs. Use decimal for traces ingested with 64-bit IDs.", ddog.TraceIDFormats))
+	cmd.Flags().StringVarP(&to, "to", "", "url", "Write the link as url or yaml, or write the trace ID in every format with ids")
+	cmd.Flags().BoolVarP(&open, "open", "", false, "Open the URL in a browser")
+	return cmd
+}
+
+// writeTraceIDs writes the trace ID in every format and the span ID.
+func writeTraceIDs(w io.Writer, id ddog.TraceID, spanID string) error {
+	for _, f := range ddog.TraceIDFormats {
+		if f == ddog.TraceIDFormatAuto {
+			continue
+		}
+		v, err := id.Format(f)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%v: %v\n", f, v)
+	}
+	if spanID != "" {
+		fmt.Fprintf(w, "spanID: %v\n", spanID)
+	}
+	return nil
+}
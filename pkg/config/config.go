@@ -50,14 +50,74 @@ type Config struct {
 
 	Logging Logging `json:"logging" yaml:"logging"`
 
-	// BaseURL is the base URL in the Honeycomb UI for your environment.
-	// This is used to construct URLs to Honeycomb queries.
+	// BaseURL is the base URL in the Datadog UI for your organization e.g. https://acme.datadoghq.com
+	// This is used to construct URLs to Datadog pages.
 	BaseURL string `json:"baseURL" yaml:"baseURL"`
 
+	// APIURL is the base URL of the Datadog API for your site e.g. https://api.datadoghq.com
+	// This is used for features that call the Datadog API such as resolving saved views.
+	APIURL string `json:"apiURL,omitempty" yaml:"apiURL,omitempty"`
+
+	// Grafana configures converting links to and from Grafana
+	Grafana *GrafanaConfig `json:"grafana,omitempty" yaml:"grafana,omitempty"`
+
+	// Honeycomb configures converting Honeycomb queries
+	Honeycomb *HoneycombConfig `json:"honeycomb,omitempty" yaml:"honeycomb,omitempty"`
+
+	// GCP configures converting between Cloud Logging filters and Datadog links
+	GCP *GCPConfig `json:"gcp,omitempty" yaml:"gcp,omitempty"`
+
+	// Kibana configures converting Kibana links
+	Kibana *KibanaConfig `json:"kibana,omitempty" yaml:"kibana,omitempty"`
+
+	// Splunk configures converting Splunk searches
+	Splunk *SplunkConfig `json:"splunk,omitempty" yaml:"splunk,omitempty"`
+
 	// configFile is the configuration file used
 	configFile string
 }
 
+// HoneycombConfig configures converting Honeycomb queries.
+type HoneycombConfig struct {
+	// ColumnMapping maps Honeycomb columns to Datadog fields e.g. app.tenant: tenant. It extends the built in mapping.
+	ColumnMapping map[string]string `json:"columnMapping,omitempty" yaml:"columnMapping,omitempty"`
+}
+
+// GCPConfig configures converting between Cloud Logging filters and Datadog links.
+type GCPConfig struct {
+	// FieldMapping maps Cloud Logging fields to Datadog fields e.g. labels.app: service. It extends the built in
+	// mapping and is inverted when exporting links to Cloud Logging.
+	FieldMapping map[string]string `json:"fieldMapping,omitempty" yaml:"fieldMapping,omitempty"`
+}
+
+// KibanaConfig configures converting Kibana links.
+type KibanaConfig struct {
+	// FieldMapping maps Elasticsearch fields to Datadog fields e.g. labels.team: team. It extends the built in mapping.
+	FieldMapping map[string]string `json:"fieldMapping,omitempty" yaml:"fieldMapping,omitempty"`
+}
+
+// SplunkConfig configures converting Splunk searches.
+type SplunkConfig struct {
+	// FieldMapping maps Splunk fields to Datadog fields e.g. app: service. It extends the built in mapping.
+	FieldMapping map[string]string `json:"fieldMapping,omitempty" yaml:"fieldMapping,omitempty"`
+}
+
+// GrafanaConfig configures converting links to and from Grafana.
+type GrafanaConfig struct {
+	// LabelMapping maps Loki labels to Datadog tags e.g. app: service. It extends the built in mapping.
+	LabelMapping map[string]string `json:"labelMapping,omitempty" yaml:"labelMapping,omitempty"`
+
+	// BaseURL is the base URL of Grafana used when exporting links e.g. https://grafana.acme.com
+	BaseURL string `json:"baseURL,omitempty" yaml:"baseURL,omitempty"`
+
+	// Datasource is the UID of the Loki datasource used when exporting links
+	Datasource string `json:"datasource,omitempty" yaml:"datasource,omitempty"`
+
+	// TagMapping maps Datadog tags to Loki labels when exporting links e.g. service: app. It extends the
+	// built in mapping and the inverse of LabelMapping.
+	TagMapping map[string]string `json:"tagMapping,omitempty" yaml:"tagMapping,omitempty"`
+}
+
 type Logging struct {
 	Level string `json:"level,omitempty" yaml:"level,omitempty"`
 	// Use JSON logging
@@ -78,6 +138,75 @@ func (c *Config) GetBaseURL() string {
 	return c.BaseURL
 }
 
+// GetAPIURL returns the URL of the Datadog API. It returns an empty string if one isn't configured.
+func (c *Config) GetAPIURL() string {
+	return strings.TrimSuffix(c.APIURL, "/")
+}
+
+// GetGrafanaLabelMapping returns the configured mapping from Loki labels to Datadog tags.
+func (c *Config) GetGrafanaLabelMapping() map[string]string {
+	if c.Grafana == nil {
+		return nil
+	}
+	return c.Grafana.LabelMapping
+}
+
+// GetGrafanaTagMapping returns the configured mapping from Datadog tags to Loki labels.
+func (c *Config) GetGrafanaTagMapping() map[string]string {
+	if c.Grafana == nil {
+		return nil
+	}
+	return c.Grafana.TagMapping
+}
+
+// GetGrafanaBaseURL returns the base URL of Grafana.
+func (c *Config) GetGrafanaBaseURL() string {
+	if c.Grafana == nil {
+		return ""
+	}
+	return strings.TrimSuffix(c.Grafana.BaseURL, "/")
+}
+
+// GetGrafanaDatasource returns the UID of the Loki datasource.
+func (c *Config) GetGrafanaDatasource() string {
+	if c.Grafana == nil {
+		return ""
+	}
+	return c.Grafana.Datasource
+}
+
+// GetHoneycombColumnMapping returns the configured mapping from Honeycomb columns to Datadog fields.
+func (c *Config) GetHoneycombColumnMapping() map[string]string {
+	if c.Honeycomb == nil {
+		return nil
+	}
+	return c.Honeycomb.ColumnMapping
+}
+
+// GetGCPFieldMapping returns the configured mapping from Cloud Logging fields to Datadog fields.
+func (c *Config) GetGCPFieldMapping() map[string]string {
+	if c.GCP == nil {
+		return nil
+	}
+	return c.GCP.FieldMapping
+}
+
+// GetKibanaFieldMapping returns the configured mapping from Elasticsearch fields to Datadog fields.
+func (c *Config) GetKibanaFieldMapping() map[string]string {
+	if c.Kibana == nil {
+		return nil
+	}
+	return c.Kibana.FieldMapping
+}
+
+// GetSplunkFieldMapping returns the configured mapping from Splunk fields to Datadog fields.
+func (c *Config) GetSplunkFieldMapping() map[string]string {
+	if c.Splunk == nil {
+		return nil
+	}
+	return c.Splunk.FieldMapping
+}
+
 func (c *Config) GetLogLevel() string {
 	if c.Logging.Level == "" {
 		return "info"
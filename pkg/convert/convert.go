@@ -0,0 +1,55 @@
+// Package convert translates queries and links from other observability tools into Datadog links.
+//
+// Translation is best effort. Constructs that can't be translated exactly are reported as warnings rather than
+// failing the conversion so a link can still be produced and fixed up by hand.
+package convert
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// Warning describes part of the source that couldn't be translated exactly.
+type Warning struct {
+	// Column is the 1 based column of the construct in the source query or 0 if it isn't part of the query.
+	Column int
+	// Construct is the source text that couldn't be translated
+	Construct string
+	Msg       string
+}
+
+func (w Warning) String() string {
+	if w.Column == 0 {
+		return fmt.Sprintf("%v: %v", w.Construct, w.Msg)
+	}
+	return fmt.Sprintf("column %d: %v: %v", w.Column, w.Construct, w.Msg)
+}
+
+// warnAt returns a warning for the construct at the byte offset in src.
+func warnAt(src string, offset int, construct string, format string, args ...any) Warning {
+	return Warning{
+		Column:    utf8.RuneCountInString(src[:offset]) + 1,
+		Construct: construct,
+		Msg:       fmt.Sprintf(format, args...),
+	}
+}
+
+// warn returns a warning for a construct that isn't part of a query e.g. a field in a query spec.
+func warn(construct string, format string, args ...any) Warning {
+	return Warning{
+		Construct: construct,
+		Msg:       fmt.Sprintf(format, args...),
+	}
+}
+
+// MergeMappings returns a new mapping containing the entries of all the mappings. Values in later mappings
+// override those in earlier ones e.g. MergeMappings(defaults, configured, flag).
+func MergeMappings(mappings ...map[string]string) map[string]string {
+	merged := map[string]string{}
+	for _, m := range mappings {
+		for k, v := range m {
+			merged[k] = v
+		}
+	}
+	return merged
+}
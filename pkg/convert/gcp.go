@@ -0,0 +1,841 @@
+package convert
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jlewi/ddctl/api"
+	"github.com/jlewi/ddctl/pkg/ddog"
+	"github.com/jlewi/ddctl/pkg/query"
+	"github.com/jlewi/grafctl/pkg/grafana"
+	"github.com/jlewi/monogo/gcp/logging"
+	"github.com/pkg/errors"
+)
+
+const (
+	gcpTimestampField = "timestamp"
+	gcpTextPayload    = "textPayload"
+	gcpJSONPayload    = "jsonPayload."
+	gcpLabels         = "labels."
+)
+
+var (
+	// DefaultGCPFieldMapping maps Cloud Logging fields to the equivalent Datadog tags and attributes.
+	// Keys ending in . map every field with that prefix e.g. jsonPayload.user becomes @user.
+	DefaultGCPFieldMapping = map[string]string{
+		logging.SeverityField:            "status",
+		"resource.labels.project_id":     "project_id",
+		"resource.labels.cluster_name":   "kube_cluster_name",
+		"resource.labels.namespace_name": "kube_namespace",
+		"resource.labels.pod_name":       "pod_name",
+		"resource.labels.container_name": "container_name",
+		"httpRequest.requestMethod":      "@http.method",
+		"httpRequest.requestUrl":         "@http.url",
+		"httpRequest.status":             "@http.status_code",
+		gcpJSONPayload:                   "@",
+		gcpLabels:                        "",
+	}
+
+	// gcpSeverities are the Cloud Logging severities in increasing order and the equivalent Datadog statuses.
+	gcpSeverities = []struct {
+		severity string
+		status   string
+	}{
+		{"DEFAULT", "ok"},
+		{"DEBUG", "debug"},
+		{"INFO", "info"},
+		{"NOTICE", "notice"},
+		{"WARNING", "warn"},
+		{"ERROR", "error"},
+		{"CRITICAL", "critical"},
+		{"ALERT", "alert"},
+		{"EMERGENCY", "emergency"},
+	}
+)
+
+// GCPOptions configures converting between Cloud Logging filters and DatadogLinks.
+type GCPOptions struct {
+	// BaseURL is the base URL of the Datadog link e.g. https://app.datadoghq.com
+	BaseURL string
+	// FieldMapping maps Cloud Logging fields to Datadog fields. It extends DefaultGCPFieldMapping and is
+	// inverted to translate DatadogLinks into filters.
+	FieldMapping map[string]string
+}
+
+// gcpFields maps fields between Cloud Logging and Datadog.
+type gcpFields map[string]string
+
+// datadog returns the Datadog field for a Cloud Logging field. Fields that aren't mapped become facets.
+func (f gcpFields) datadog(field string) string {
+	if key, ok := f[field]; ok {
+		return key
+	}
+	if prefix := f.longestPrefix(field); prefix != "" {
+		return f[prefix] + strings.TrimPrefix(field, prefix)
+	}
+	return "@" + field
+}
+
+func (f gcpFields) longestPrefix(field string) string {
+	longest := ""
+	for k := range f {
+		if strings.HasSuffix(k, ".") && strings.HasPrefix(field, k) && len(k) > len(longest) {
+			longest = k
+		}
+	}
+	return longest
+}
+
+// gcp returns the Cloud Logging field for a Datadog field. Tags that aren't mapped become labels.
+func (f gcpFields) gcp(key string) string {
+	// Sort the mapping so the result is deterministic if several fields map to the same key
+	fields := make([]string, 0, len(f))
+	for k := range f {
+		fields = append(fields, k)
+	}
+	sort.Strings(fields)
+
+	prefix := ""
+	longest := ""
+	for _, field := range fields {
+		target := f[field]
+		if !strings.HasSuffix(field, ".") {
+			if target == key {
+				return field
+			}
+			continue
+		}
+		if strings.HasPrefix(key, target) && (prefix == "" || len(target) > len(longest)) {
+			prefix = field
+			longest = target
+		}
+	}
+	if prefix != "" {
+		return prefix + strings.TrimPrefix(key, longest)
+	}
+	if strings.HasPrefix(key, "@") {
+		return gcpJSONPayload + key[1:]
+	}
+	return gcpLabels + key
+}
+
+// isMessage returns true if matching the Cloud Logging field is free text in Datadog.
+func isMessage(field string) bool {
+	return field == gcpTextPayload || field == gcpJSONPayload+logging.MessageField
+}
+
+// GCPFilterToLink translates a Cloud Logging query (https://cloud.google.com/logging/docs/view/logging-query-language)
+// into a DatadogLink.
+//
+// Comparisons (=, !=, >, >=, <, <=, :, =~), AND, OR, NOT, parentheses and free text are supported. Restrictions
+// on timestamp set the time range of the link and severity comparisons become the equivalent statuses e.g.
+// severity>=ERROR becomes status:(error OR critical OR alert OR emergency). Functions such as log_id and
+// regular expressions that can't be expressed as wildcards are reported as warnings.
+func GCPFilterToLink(filter string, opts GCPOptions) (*api.DatadogLink, []Warning, error) {
+	p := &gcpParser{
+		src:    filter,
+		fields: MergeMappings(DefaultGCPFieldMapping, opts.FieldMapping),
+		link: &api.DatadogLink{
+			APIVersion: api.LinkGVK.GroupVersion().String(),
+			Kind:       api.LinkGVK.Kind,
+			BaseURL:    opts.BaseURL,
+		},
+	}
+
+	b := ddog.Q()
+	p.skipSpace()
+	if !p.eof() {
+		n, err := p.parseAnd()
+		if err != nil {
+			return nil, nil, err
+		}
+		p.skipSpace()
+		if !p.eof() {
+			return nil, nil, p.errorf("unexpected %q", p.peek())
+		}
+		if n != nil {
+			b.Term(n)
+		}
+	}
+
+	p.link.Query = b.String()
+	return p.link, p.warnings, nil
+}
+
+type gcpParser struct {
+	src      string
+	pos      int
+	fields   gcpFields
+	link     *api.DatadogLink
+	warnings []Warning
+}
+
+func (p *gcpParser) errorf(format string, args ...any) error {
+	return errors.Errorf("invalid filter at column %d: %v", p.pos+1, errors.Errorf(format, args...))
+}
+
+func (p *gcpParser) warn(start int, format string, args ...any) {
+	construct := strings.TrimSpace(p.src[start:p.pos])
+	p.warnings = append(p.warnings, warnAt(p.src, start, construct, format, args...))
+}
+
+func (p *gcpParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *gcpParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+// skipSpace skips whitespace and -- comments.
+func (p *gcpParser) skipSpace() {
+	for !p.eof() {
+		switch {
+		case strings.ContainsRune(" \t\n\r", rune(p.peek())):
+			p.pos++
+		case strings.HasPrefix(p.src[p.pos:], "--"):
+			end := strings.IndexByte(p.src[p.pos:], '\n')
+			if end < 0 {
+				p.pos = len(p.src)
+			} else {
+				p.pos += end
+			}
+		default:
+			return
+		}
+	}
+}
+
+// peekKeyword returns true if the keyword is next and is followed by a delimiter. Keywords are uppercase.
+func (p *gcpParser) peekKeyword(kw string) bool {
+	p.skipSpace()
+	if !strings.HasPrefix(p.src[p.pos:], kw) {
+		return false
+	}
+	end := p.pos + len(kw)
+	return end == len(p.src) || strings.ContainsRune(" \t\n\r()\"", rune(p.src[end]))
+}
+
+func (p *gcpParser) atEnd() bool {
+	p.skipSpace()
+	return p.eof() || p.peek() == ')'
+}
+
+// parseAnd parses an expression. Unlike Datadog, OR binds more tightly than AND and whitespace between terms
+// is an implicit AND.
+func (p *gcpParser) parseAnd() (query.Node, error) {
+	var operands []query.Node
+	for !p.atEnd() {
+		if p.peekKeyword("AND") {
+			p.pos += len("AND")
+			if p.atEnd() {
+				return nil, p.errorf("expected a term after AND")
+			}
+			continue
+		}
+
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if n != nil {
+			operands = append(operands, n)
+		}
+	}
+	return andOf(operands), nil
+}
+
+func (p *gcpParser) parseOr() (query.Node, error) {
+	var operands []query.Node
+	for {
+		n, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		if n != nil {
+			operands = append(operands, n)
+		}
+		if !p.peekKeyword("OR") {
+			break
+		}
+		p.pos += len("OR")
+		if p.atEnd() {
+			return nil, p.errorf("expected a term after OR")
+		}
+	}
+
+	switch len(operands) {
+	case 0:
+		return nil, nil
+	case 1:
+		return operands[0], nil
+	}
+	return &query.Or{Operands: operands}, nil
+}
+
+func (p *gcpParser) parseNot() (query.Node, error) {
+	negate := false
+	switch {
+	case p.peekKeyword("NOT"):
+		p.pos += len("NOT")
+		negate = true
+	case p.peek() == '-':
+		p.pos++
+		negate = true
+	}
+	if !negate {
+		return p.parsePrimary()
+	}
+
+	if p.atEnd() {
+		return nil, p.errorf("expected a term after NOT")
+	}
+	n, err := p.parseNot()
+	if err != nil || n == nil {
+		return nil, err
+	}
+	return &query.Not{Operand: n}, nil
+}
+
+func (p *gcpParser) parsePrimary() (query.Node, error) {
+	p.skipSpace()
+	start := p.pos
+	if p.peek() == '(' {
+		p.pos++
+		n, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if p.peek() != ')' {
+			p.pos = start
+			return nil, p.errorf("missing ) to close (")
+		}
+		p.pos++
+		return n, nil
+	}
+
+	value, quoted, err := p.readValue(true)
+	if err != nil {
+		return nil, err
+	}
+	if quoted {
+		return query.Literal(value), nil
+	}
+
+	p.skipSpace()
+	if p.peek() == '(' {
+		// A function e.g. log_id("stdout")
+		p.skipArgs()
+		p.warn(start, "functions aren't supported")
+		return nil, nil
+	}
+
+	op := p.readOp()
+	if op == "" {
+		return gcpText(value), nil
+	}
+	return p.parseComparison(start, value, op)
+}
+
+// skipArgs skips the parenthesized arguments of a function.
+func (p *gcpParser) skipArgs() {
+	depth := 0
+	for !p.eof() {
+		switch p.peek() {
+		case '"':
+			if _, _, err := p.readValue(false); err != nil {
+				p.pos = len(p.src)
+			}
+			continue
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				p.pos++
+				return
+			}
+		}
+		p.pos++
+	}
+}
+
+// readOp reads one of the comparison operators.
+func (p *gcpParser) readOp() string {
+	p.skipSpace()
+	for _, op := range []string{"=~", "!~", "!=", ">=", "<=", "=", ">", "<", ":"} {
+		if strings.HasPrefix(p.src[p.pos:], op) {
+			p.pos += len(op)
+			return op
+		}
+	}
+	return ""
+}
+
+// readValue reads a quoted string or an unquoted value. If isField is true quoted path segments such as
+// labels."k8s-pod/app" are part of the value.
+func (p *gcpParser) readValue(isField bool) (string, bool, error) {
+	p.skipSpace()
+	if p.peek() == '"' {
+		s, err := p.readString()
+		return s, true, err
+	}
+
+	start := p.pos
+	for !p.eof() {
+		c := p.peek()
+		if c == '"' && isField && p.pos > start && p.src[p.pos-1] == '.' {
+			if _, err := p.readString(); err != nil {
+				return "", false, err
+			}
+			continue
+		}
+		if strings.ContainsRune(" \t\n\r()\"=!<>:", rune(c)) {
+			break
+		}
+		if c == '\\' {
+			p.pos++
+		}
+		p.pos++
+	}
+	if p.pos == start {
+		return "", false, p.errorf("unexpected %q; expected a value", p.peek())
+	}
+	return strings.ReplaceAll(p.src[start:p.pos], `"`, ""), false, nil
+}
+
+func (p *gcpParser) readString() (string, error) {
+	start := p.pos
+	for p.pos++; !p.eof() && p.peek() != '"'; p.pos++ {
+		if p.peek() == '\\' {
+			p.pos++
+		}
+	}
+	if p.eof() {
+		p.pos = start
+		return "", p.errorf("unterminated string")
+	}
+	p.pos++
+	value, err := strconv.Unquote(p.src[start:p.pos])
+	if err != nil {
+		return p.src[start+1 : p.pos-1], nil
+	}
+	return value, nil
+}
+
+func (p *gcpParser) parseComparison(start int, field string, op string) (query.Node, error) {
+	p.skipSpace()
+	if p.peek() == '(' {
+		// A list of values e.g. severity=(ERROR OR WARNING)
+		p.pos++
+		var values []query.Node
+		for !p.atEnd() {
+			if p.peekKeyword("OR") {
+				p.pos += len("OR")
+				continue
+			}
+			value, quoted, err := p.readValue(false)
+			if err != nil {
+				return nil, err
+			}
+			if n := p.comparison(start, field, op, value, quoted); n != nil {
+				values = append(values, n)
+			}
+		}
+		if p.peek() != ')' {
+			return nil, p.errorf("missing ) to close the values of %v", field)
+		}
+		p.pos++
+		switch len(values) {
+		case 0:
+			return nil, nil
+		case 1:
+			return values[0], nil
+		}
+		return &query.Or{Operands: values}, nil
+	}
+
+	value, quoted, err := p.readValue(false)
+	if err != nil {
+		return nil, err
+	}
+	return p.comparison(start, field, op, value, quoted), nil
+}
+
+// comparison translates a single comparison e.g. jsonPayload.user="alice".
+func (p *gcpParser) comparison(start int, field string, op string, value string, quoted bool) query.Node {
+	if field == gcpTimestampField {
+		p.timestamp(start, op, value)
+		return nil
+	}
+
+	negate := false
+	switch op {
+	case "!=":
+		op, negate = "=", true
+	case "!~":
+		op, negate = "=~", true
+	}
+
+	var n query.Node
+	switch {
+	case field == logging.SeverityField && p.fields.datadog(field) == "status":
+		n = p.severity(start, op, value)
+	case op == "=~":
+		// =~ matches anywhere in the value unless the regex is anchored
+		pattern, ok := translateRegex(value, regexPartialMatch)
+		if !ok {
+			p.warn(start, "the regular expression can't be expressed with wildcards")
+			return nil
+		}
+		if pattern.FoldCase {
+			p.warn(start, foldCaseWarning)
+		}
+		values := make([]query.Node, 0, len(pattern.Values))
+		for _, v := range pattern.Values {
+			values = append(values, v)
+		}
+		n = p.match(field, orValues(values))
+	case op == ":" && value == "*" && !quoted:
+		n = &query.Field{Key: p.fields.datadog(field), Value: &query.Text{Value: "*"}}
+	case op == ":":
+		// : matches a substring
+		n = p.match(field, query.Wildcard("*"+value+"*"))
+	case op == "=":
+		n = p.match(field, query.Literal(value))
+	default:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			p.warn(start, "only numeric comparisons are supported")
+			return nil
+		}
+		n = p.match(field, &query.Comparison{Op: op, Value: value})
+	}
+
+	if n != nil && negate {
+		return &query.Not{Operand: n}
+	}
+	return n
+}
+
+// match returns the node matching the value of a Cloud Logging field. Matching the message becomes free text.
+func (p *gcpParser) match(field string, value query.Node) query.Node {
+	if isMessage(field) {
+		return value
+	}
+	return &query.Field{Key: p.fields.datadog(field), Value: value}
+}
+
+// orValues returns the value matching any of values.
+func orValues(values []query.Node) query.Node {
+	if len(values) == 1 {
+		return values[0]
+	}
+	return &query.Group{Expr: &query.Or{Operands: values}}
+}
+
+// severity translates a severity comparison into the matching statuses.
+func (p *gcpParser) severity(start int, op string, value string) query.Node {
+	level := -1
+	for i, s := range gcpSeverities {
+		if strings.EqualFold(s.severity, value) {
+			level = i
+		}
+	}
+	if level < 0 {
+		p.warn(start, "unknown severity %v", value)
+		return nil
+	}
+
+	var statuses []query.Node
+	for i, s := range gcpSeverities {
+		matches := false
+		switch op {
+		case "=", ":":
+			matches = i == level
+		case ">":
+			matches = i > level
+		case ">=":
+			matches = i >= level
+		case "<":
+			matches = i < level
+		case "<=":
+			matches = i <= level
+		default:
+			p.warn(start, "the operator %v isn't supported for severity", op)
+			return nil
+		}
+		if matches {
+			statuses = append(statuses, query.Literal(s.status))
+		}
+	}
+	if len(statuses) == 0 {
+		p.warn(start, "no severities match")
+		return nil
+	}
+	return &query.Field{Key: p.fields.datadog(logging.SeverityField), Value: orValues(statuses)}
+}
+
+// timestamp translates a restriction on the timestamp into the time range of the link.
+func (p *gcpParser) timestamp(start int, op string, value string) {
+	ts, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		p.warn(start, "only RFC 3339 timestamps are supported")
+		return
+	}
+	ms := strconv.FormatInt(ts.UnixMilli(), 10)
+	switch op {
+	case ">", ">=":
+		p.link.FromTS = ms
+	case "<", "<=":
+		p.link.ToTS = ms
+	default:
+		p.warn(start, "only >, >=, < and <= are supported for the timestamp")
+	}
+}
+
+// gcpText returns free text. Unquoted free text in Cloud Logging matches a substring of any field.
+func gcpText(value string) *query.Text {
+	return query.Literal(value)
+}
+
+// LinkToGCPFilter translates a DatadogLink into a Cloud Logging query.
+//
+// Each term of the query becomes a restriction on its own line. Wildcards become regular expressions,
+// statuses become severities and the time range becomes restrictions on timestamp; relative times are resolved
+// to absolute times just as they are when building a Datadog URL. Indexes and aggregations can't be expressed in
+// a filter and are reported as warnings.
+func LinkToGCPFilter(link *api.DatadogLink, opts GCPOptions) (string, []Warning, error) {
+	w := &gcpWriter{fields: MergeMappings(DefaultGCPFieldMapping, opts.FieldMapping)}
+
+	var lines []string
+	if link.Query != "" {
+		n, err := query.Parse(link.Query)
+		if err != nil {
+			return "", nil, errors.Wrapf(err, "Failed to parse query %v", link.Query)
+		}
+		conjuncts := []query.Node{n}
+		if and, ok := n.(*query.And); ok {
+			conjuncts = and.Operands
+		}
+		for _, c := range conjuncts {
+			if s := w.write(c); s != "" {
+				lines = append(lines, s)
+			}
+		}
+	}
+
+	for _, t := range []struct {
+		value string
+		op    string
+	}{{link.FromTS, ">="}, {link.ToTS, "<="}} {
+		if t.value == "" {
+			continue
+		}
+		ts, err := gcpTime(t.value)
+		if err != nil {
+			return "", nil, err
+		}
+		lines = append(lines, fmt.Sprintf("%v%v%q", gcpTimestampField, t.op, ts.UTC().Format(time.RFC3339Nano)))
+	}
+
+	if len(link.Indexes) > 0 {
+		w.warnings = append(w.warnings, warn("indexes "+strings.Join(link.Indexes, ","), "indexes can't be expressed in a filter"))
+	}
+	if link.GroupBy != "" || link.AggType != "" || len(link.Queries) > 0 {
+		w.warnings = append(w.warnings, warn("viz "+link.VisualizeAs, "aggregations can't be expressed in a filter"))
+	}
+	return strings.Join(lines, "\n"), w.warnings, nil
+}
+
+// gcpTime returns the time of a link timestamp; either milliseconds since the epoch or a relative time.
+func gcpTime(value string) (time.Time, error) {
+	if strings.Contains(value, "now") {
+		t, err := grafana.NewRelativeTimeParser().ParseGrafanaRelativeTime(value)
+		if err != nil {
+			return time.Time{}, errors.Wrapf(err, "Error parsing relative time %v", value)
+		}
+		return t, nil
+	}
+	ms, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "Invalid timestamp %v; expected milliseconds since the epoch", value)
+	}
+	return time.UnixMilli(ms), nil
+}
+
+type gcpWriter struct {
+	fields   gcpFields
+	warnings []Warning
+}
+
+// write returns the filter for a node or "" if the node can't be translated.
+func (w *gcpWriter) write(n query.Node) string {
+	switch v := n.(type) {
+	case *query.Text:
+		if v.HasWildcard() {
+			w.warnings = append(w.warnings, warn(v.String(), "wildcards in free text aren't supported"))
+			return ""
+		}
+		return strconv.Quote(unescape(v))
+	case *query.Field:
+		return w.writeField(v)
+	case *query.Not:
+		s := w.write(v.Operand)
+		if s == "" {
+			return ""
+		}
+		return "NOT " + s
+	case *query.Group:
+		s := w.write(v.Expr)
+		if s == "" {
+			return ""
+		}
+		return "(" + s + ")"
+	case *query.And:
+		return w.join(v.Operands, " AND ")
+	case *query.Or:
+		return w.join(v.Operands, " OR ")
+	}
+	w.warnings = append(w.warnings, warn(n.String(), "the term isn't supported"))
+	return ""
+}
+
+// join joins the translated operands. Operands that can't be translated are dropped. OR binds more tightly than
+// AND in Cloud Logging so nested expressions are always parenthesized.
+func (w *gcpWriter) join(operands []query.Node, sep string) string {
+	var parts []string
+	for _, o := range operands {
+		s := w.write(o)
+		if s == "" {
+			continue
+		}
+		switch o.(type) {
+		case *query.And, *query.Or:
+			s = "(" + s + ")"
+		}
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, sep)
+}
+
+func (w *gcpWriter) writeField(f *query.Field) string {
+	field := w.fields.gcp(f.Key)
+	if g, ok := f.Value.(*query.Group); ok {
+		// Distribute the field over the values e.g. status:(error OR warn)
+		values := []query.Node{g.Expr}
+		sep := " AND "
+		switch expr := g.Expr.(type) {
+		case *query.Or:
+			values = expr.Operands
+			sep = " OR "
+		case *query.And:
+			values = expr.Operands
+		}
+		var parts []string
+		for _, v := range values {
+			if s := w.writeField(&query.Field{Key: f.Key, Value: v}); s != "" {
+				parts = append(parts, s)
+			}
+		}
+		if len(parts) == 0 {
+			return ""
+		}
+		if len(parts) == 1 {
+			return parts[0]
+		}
+		return "(" + strings.Join(parts, sep) + ")"
+	}
+
+	switch v := f.Value.(type) {
+	case *query.Text:
+		if v.Value == "*" && !v.Quoted {
+			return field + ":*"
+		}
+		if field == logging.SeverityField {
+			for _, s := range gcpSeverities {
+				if strings.EqualFold(s.status, v.Value) {
+					return field + "=" + s.severity
+				}
+			}
+			w.warnings = append(w.warnings, warn(f.String(), "unknown status"))
+			return ""
+		}
+		if v.HasWildcard() {
+			return fmt.Sprintf("%v=~%q", field, "^"+wildcardToRegex(v.Value)+"$")
+		}
+		return fmt.Sprintf("%v=%q", field, unescape(v))
+	case *query.Comparison:
+		return field + v.Op + v.Value
+	case *query.Range:
+		var parts []string
+		if v.Low != "*" {
+			op := ">="
+			if v.ExclusiveLow {
+				op = ">"
+			}
+			parts = append(parts, field+op+v.Low)
+		}
+		if v.High != "*" {
+			op := "<="
+			if v.ExclusiveHigh {
+				op = "<"
+			}
+			parts = append(parts, field+op+v.High)
+		}
+		if len(parts) > 1 {
+			return "(" + strings.Join(parts, " AND ") + ")"
+		}
+		return strings.Join(parts, " AND ")
+	}
+	w.warnings = append(w.warnings, warn(f.String(), "the value isn't supported"))
+	return ""
+}
+
+// unescape returns the value of text with the escape sequences of unquoted text removed.
+func unescape(t *query.Text) string {
+	if t.Quoted {
+		return t.Value
+	}
+	var sb strings.Builder
+	escaped := false
+	for _, r := range t.Value {
+		if r == '\\' && !escaped {
+			escaped = true
+			continue
+		}
+		escaped = false
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// wildcardToRegex converts a Datadog wildcard pattern into a regular expression matching the same values.
+// The expression isn't anchored.
+func wildcardToRegex(pattern string) string {
+	var sb strings.Builder
+	escaped := false
+	for _, r := range pattern {
+		switch {
+		case escaped:
+			escaped = false
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		case r == '\\':
+			escaped = true
+		case r == '*':
+			sb.WriteString(".*")
+		case r == '?':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	return sb.String()
+}
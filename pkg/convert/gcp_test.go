@@ -0,0 +1,174 @@
+package convert
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jlewi/ddctl/api"
+)
+
+func TestGCPFilterToLink(t *testing.T) {
+	type testCase struct {
+		Name             string
+		Input            string
+		Expected         *api.DatadogLink
+		ExpectedWarnings []string
+	}
+
+	cases := []testCase{
+		{
+			Name: "basic",
+			Input: `resource.type="k8s_container"
+resource.labels.namespace_name="shop"
+-- Only errors
+severity>=ERROR
+jsonPayload.user="alice" AND NOT labels.env=dev
+timestamp>="2025-01-15T07:58:49.003Z" timestamp<="2025-01-15T13:58:49.003Z"`,
+			Expected: &api.DatadogLink{
+				Query:  `@resource.type:k8s_container kube_namespace:shop status:(error OR critical OR alert OR emergency) @user:alice -env:dev`,
+				FromTS: "1736927929003",
+				ToTS:   "1736949529003",
+			},
+		},
+		{
+			Name:  "precedence-and-operators",
+			Input: `jsonPayload.message:"timeout" httpRequest.status>=500 jsonPayload.path=~"^/api/.*" OR severity=WARNING log_id("stdout")`,
+			Expected: &api.DatadogLink{
+				Query: `*timeout* @http.status_code:>=500 (@path:\/api\/* OR status:warn)`,
+			},
+			ExpectedWarnings: []string{
+				`column 104: log_id("stdout"): functions aren't supported`,
+			},
+		},
+		{
+			Name: "regex-anchors",
+			Input: `jsonPayload.msg=~"timeout"
+labels.app=~"^web"
+jsonPayload.path=~"/healthz$"
+labels.env=~"^prod$|staging"`,
+			Expected: &api.DatadogLink{
+				Query: `@msg:*timeout* app:web* @path:*\/healthz env:(prod OR *staging*)`,
+			},
+		},
+		{
+			Name:  "regex-case-insensitive",
+			Input: `jsonPayload.msg=~"(?i)timeout"`,
+			Expected: &api.DatadogLink{
+				Query: `@msg:*timeout*`,
+			},
+			ExpectedWarnings: []string{
+				`column 1: jsonPayload.msg=~"(?i)timeout": the regular expression is case insensitive but Datadog values only match the case they are written in`,
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			actual, warnings, err := GCPFilterToLink(c.Input, GCPOptions{BaseURL: "https://acme.datadoghq.com"})
+			if err != nil {
+				t.Fatalf("Failed to convert %v: %+v", c.Input, err)
+			}
+
+			c.Expected.APIVersion = api.LinkGVK.GroupVersion().String()
+			c.Expected.Kind = api.LinkGVK.Kind
+			c.Expected.BaseURL = "https://acme.datadoghq.com"
+			if d := cmp.Diff(c.Expected, actual); d != "" {
+				t.Errorf("Link doesn't match; diff\n%v", d)
+			}
+
+			var actualWarnings []string
+			for _, w := range warnings {
+				actualWarnings = append(actualWarnings, w.String())
+			}
+			if d := cmp.Diff(c.ExpectedWarnings, actualWarnings); d != "" {
+				t.Errorf("Warnings don't match; diff\n%v", d)
+			}
+		})
+	}
+}
+
+func TestLinkToGCPFilter(t *testing.T) {
+	type testCase struct {
+		Name             string
+		Input            *api.DatadogLink
+		Mapping          map[string]string
+		Expected         string
+		ExpectedWarnings []string
+	}
+
+	cases := []testCase{
+		{
+			Name: "basic",
+			Input: &api.DatadogLink{
+				Query:  `"connection reset" kube_namespace:shop status:(error OR warn) -@http.method:GET @duration:[100 TO 200} @user.id:* service:feserver*`,
+				FromTS: "1736927929003",
+				ToTS:   "1736949529003",
+			},
+			Mapping: map[string]string{`labels."k8s-pod/app"`: "service"},
+			Expected: `"connection reset"
+resource.labels.namespace_name="shop"
+(severity=ERROR OR severity=WARNING)
+NOT httpRequest.requestMethod="GET"
+(jsonPayload.duration>=100 AND jsonPayload.duration<200)
+jsonPayload.user.id:*
+labels."k8s-pod/app"=~"^feserver.*$"
+timestamp>="2025-01-15T07:58:49.003Z"
+timestamp<="2025-01-15T13:58:49.003Z"`,
+		},
+		{
+			Name: "or-and-warnings",
+			Input: &api.DatadogLink{
+				Query:   `env:prod (a OR b c) *timeout*`,
+				Indexes: []string{"main"},
+				GroupBy: "service",
+			},
+			Expected: `labels.env="prod"
+("a" OR ("b" AND "c"))`,
+			ExpectedWarnings: []string{
+				"*timeout*: wildcards in free text aren't supported",
+				"indexes main: indexes can't be expressed in a filter",
+				"viz : aggregations can't be expressed in a filter",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			actual, warnings, err := LinkToGCPFilter(c.Input, GCPOptions{FieldMapping: c.Mapping})
+			if err != nil {
+				t.Fatalf("Failed to convert %v: %+v", c.Input.Query, err)
+			}
+			if actual != c.Expected {
+				t.Errorf("Filter doesn't match; got\n%v\nwant\n%v", actual, c.Expected)
+			}
+
+			var actualWarnings []string
+			for _, w := range warnings {
+				actualWarnings = append(actualWarnings, w.String())
+			}
+			if d := cmp.Diff(c.ExpectedWarnings, actualWarnings); d != "" {
+				t.Errorf("Warnings don't match; diff\n%v", d)
+			}
+		})
+	}
+}
+
+func TestGCPRoundTrip(t *testing.T) {
+	// Exported regexes are anchored so importing them gives back the same wildcards
+	query := `@msg:*timeout* app:web* @path:*healthz`
+	filter, _, err := LinkToGCPFilter(&api.DatadogLink{Query: query}, GCPOptions{})
+	if err != nil {
+		t.Fatalf("Failed to export %v: %+v", query, err)
+	}
+
+	link, warnings, err := GCPFilterToLink(filter, GCPOptions{})
+	if err != nil {
+		t.Fatalf("Failed to import %v: %+v", filter, err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("Unexpected warnings: %v", warnings)
+	}
+	if link.Query != query {
+		t.Errorf("Query doesn't round trip; got %v via %v; want %v", link.Query, filter, query)
+	}
+}
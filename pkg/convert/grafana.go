@@ -0,0 +1,169 @@
+package convert
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/jlewi/ddctl/api"
+	gapi "github.com/jlewi/grafctl/api"
+	"github.com/jlewi/grafctl/pkg/grafana"
+	"github.com/pkg/errors"
+)
+
+const (
+	// lokiExprField is the field of a Grafana query containing the LogQL expression
+	lokiExprField = "expr"
+	// lokiDatasourceType is the type of Loki datasources
+	lokiDatasourceType = "loki"
+	// grafanaPaneID is the ID of the Explore pane in exported links
+	grafanaPaneID = "ddctl"
+)
+
+// GrafanaOptions configures converting Grafana links.
+type GrafanaOptions struct {
+	// BaseURL is the base URL of the Datadog link e.g. https://app.datadoghq.com
+	BaseURL string
+	// LabelMapping maps Loki labels to Datadog tags. It extends DefaultLabelMapping.
+	LabelMapping map[string]string
+}
+
+// GrafanaURLToLink converts a Grafana Explore URL with a Loki query into a DatadogLink.
+func GrafanaURLToLink(u string, opts GrafanaOptions) (*api.DatadogLink, []Warning, error) {
+	baseURL, _, panes, err := grafana.ParseURL(u)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(panes) != 1 {
+		return nil, nil, errors.Errorf("Expected the URL to have 1 panes parameter but got %v", len(panes))
+	}
+
+	link := &gapi.GrafanaLink{
+		BaseURL: baseURL,
+		Panes:   *panes[0],
+	}
+	return GrafanaLinkToLink(link, opts)
+}
+
+// GrafanaLinkToLink converts a GrafanaLink with a Loki query into a DatadogLink.
+// Only the first Loki query is translated; any other panes and queries are reported as warnings.
+func GrafanaLinkToLink(glink *gapi.GrafanaLink, opts GrafanaOptions) (*api.DatadogLink, []Warning, error) {
+	ids := make([]string, 0, len(glink.Panes))
+	for id := range glink.Panes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var warnings []Warning
+	var pane *gapi.PaneBody
+	var expr string
+	for _, id := range ids {
+		p := glink.Panes[id]
+		for _, q := range p.Queries {
+			e, ok := q.AdditionalFields[lokiExprField].(string)
+			if !ok || strings.TrimSpace(e) == "" {
+				continue
+			}
+			if pane != nil {
+				warnings = append(warnings, warn(e, "only the first Loki query is translated"))
+				continue
+			}
+			pane = &p
+			expr = e
+		}
+	}
+
+	if pane == nil {
+		return nil, nil, errors.New("The Grafana link doesn't have a Loki query; only Loki queries can be converted")
+	}
+
+	result, err := LogQLToQuery(expr, opts.LabelMapping)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	link := &api.DatadogLink{
+		APIVersion: api.LinkGVK.GroupVersion().String(),
+		Kind:       api.LinkGVK.Kind,
+		Metadata: api.Metadata{
+			Name: glink.Metadata.Name,
+		},
+		BaseURL: opts.BaseURL,
+		Query:   result.Query,
+		FromTS:  pane.Range.From,
+		ToTS:    pane.Range.To,
+	}
+
+	if result.AggType != "" {
+		link.VisualizeAs = "timeseries"
+		link.GroupInto = "count"
+		link.AggType = result.AggType
+		link.GroupBy = strings.Join(result.GroupBy, ",")
+	}
+
+	return link, append(result.Warnings, warnings...), nil
+}
+
+// GrafanaExportOptions configures exporting DatadogLinks to Grafana.
+type GrafanaExportOptions struct {
+	// BaseURL is the base URL of Grafana e.g. https://grafana.acme.com
+	BaseURL string
+	// Datasource is the UID of the Loki datasource
+	Datasource string
+	// TagMapping maps Datadog tags to Loki labels. It extends DefaultTagMapping.
+	TagMapping map[string]string
+}
+
+// LinkToGrafanaLink converts a DatadogLink into a GrafanaLink for Explore with the equivalent LogQL query.
+// See LinkToLogQL for how the query is translated.
+func LinkToGrafanaLink(link *api.DatadogLink, opts GrafanaExportOptions) (*gapi.GrafanaLink, []Warning, error) {
+	result, err := LinkToLogQL(link, opts.TagMapping)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	queryType := "range"
+	if link.AggType == "" {
+		// Log queries show the matching lines rather than a graph
+		queryType = ""
+	}
+
+	from := link.FromTS
+	if from == "" {
+		from = "now-15m"
+	}
+	to := link.ToTS
+	if to == "" {
+		to = "now"
+	}
+
+	warnings := result.Warnings
+	if len(link.Columns) > 0 {
+		warnings = append(warnings, warn("columns "+strings.Join(link.Columns, ","), "columns aren't exported"))
+	}
+
+	glink := &gapi.GrafanaLink{
+		APIVersion: gapi.LinkGVK.GroupVersion().String(),
+		Kind:       gapi.LinkGVK.Kind,
+		Metadata: gapi.Metadata{
+			Name: link.Metadata.Name,
+		},
+		BaseURL: opts.BaseURL,
+		Panes: gapi.Panes{
+			grafanaPaneID: gapi.PaneBody{
+				Datasource: opts.Datasource,
+				Queries: []*gapi.Query{
+					{
+						RefID:      "A",
+						Datasource: gapi.Datasource{Type: lokiDatasourceType, UID: opts.Datasource},
+						QueryType:  queryType,
+						AdditionalFields: map[string]any{
+							lokiExprField: result.Expr,
+						},
+					},
+				},
+				Range: gapi.TimeRange{From: from, To: to},
+			},
+		},
+	}
+	return glink, warnings, nil
+}
@@ -0,0 +1,49 @@
+package convert
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jlewi/ddctl/api"
+	gapi "github.com/jlewi/grafctl/api"
+	"github.com/jlewi/grafctl/pkg/grafana"
+	yaml "sigs.k8s.io/yaml/goyaml.v3"
+)
+
+func TestGrafanaURLToLink(t *testing.T) {
+	raw, err := os.ReadFile(filepath.Join("test_data", "grafana_loki.yaml"))
+	if err != nil {
+		t.Fatalf("Failed to read test data: %v", err)
+	}
+	glink := &gapi.GrafanaLink{}
+	if err := yaml.Unmarshal(raw, glink); err != nil {
+		t.Fatalf("Failed to unmarshal GrafanaLink: %v", err)
+	}
+
+	u, err := grafana.LinkToURL(*glink)
+	if err != nil {
+		t.Fatalf("Failed to build the Grafana URL: %v", err)
+	}
+
+	actual, warnings, err := GrafanaURLToLink(u, GrafanaOptions{BaseURL: "https://acme.datadoghq.com"})
+	if err != nil {
+		t.Fatalf("Failed to convert %v: %+v", u, err)
+	}
+
+	expected := &api.DatadogLink{
+		APIVersion: api.LinkGVK.GroupVersion().String(),
+		Kind:       api.LinkGVK.Kind,
+		BaseURL:    "https://acme.datadoghq.com",
+		Query:      "service:feserver kube_namespace:prod error @status:>=500",
+		FromTS:     "now-1h",
+		ToTS:       "now",
+	}
+	if d := cmp.Diff(expected, actual); d != "" {
+		t.Errorf("Link doesn't match; diff\n%v", d)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("Expected no warnings but got %v", warnings)
+	}
+}
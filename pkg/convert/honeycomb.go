@@ -0,0 +1,392 @@
+package convert
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/jlewi/ddctl/api"
+	"github.com/jlewi/ddctl/pkg/ddog"
+	"github.com/jlewi/ddctl/pkg/query"
+	"github.com/pkg/errors"
+)
+
+const (
+	// TargetTraces converts queries into DatadogTraceSearch links
+	TargetTraces = "traces"
+	// TargetLogs converts queries into DatadogLink links
+	TargetLogs = "logs"
+)
+
+var (
+	// DefaultColumnMapping maps common Honeycomb columns to the equivalent Datadog fields.
+	DefaultColumnMapping = map[string]string{
+		"service.name":     "service",
+		"service_name":     "service",
+		"name":             "resource_name",
+		"host.name":        "host",
+		"trace.trace_id":   "trace_id",
+		"trace.span_id":    "span_id",
+		"trace.parent_id":  "parent_id",
+		"duration_ms":      "@duration",
+		"http.status_code": "@http.status_code",
+		"http.method":      "@http.method",
+		"http.route":       "@http.route",
+	}
+
+	// columnScales are the factors to multiply the values of columns by to convert them to the unit of the
+	// Datadog field. Honeycomb durations are in milliseconds and Datadog durations are in nanoseconds.
+	columnScales = map[string]float64{
+		"duration_ms": 1e6,
+	}
+
+	// honeycombAggTypes maps Honeycomb calculations to Datadog aggregation types
+	honeycombAggTypes = map[string]string{
+		"COUNT":          "count",
+		"COUNT_DISTINCT": "cardinality",
+		"SUM":            "sum",
+		"AVG":            "avg",
+		"MIN":            "min",
+		"MAX":            "max",
+		"P50":            "median",
+		"P75":            "pc75",
+		"P90":            "pc90",
+		"P95":            "pc95",
+		"P99":            "pc99",
+	}
+)
+
+// HoneycombQuery is a Honeycomb query specification.
+// https://docs.honeycomb.io/api/query-specification/
+type HoneycombQuery struct {
+	Calculations      []HoneycombCalculation `json:"calculations,omitempty"`
+	Filters           []HoneycombFilter      `json:"filters,omitempty"`
+	FilterCombination string                 `json:"filter_combination,omitempty"`
+	Breakdowns        []string               `json:"breakdowns,omitempty"`
+	Orders            []HoneycombOrder       `json:"orders,omitempty"`
+	Havings           []HoneycombHaving      `json:"havings,omitempty"`
+	Limit             int                    `json:"limit,omitempty"`
+	// TimeRange is the number of seconds before EndTime or now
+	TimeRange int `json:"time_range,omitempty"`
+	// StartTime and EndTime are unix timestamps in seconds
+	StartTime   int64 `json:"start_time,omitempty"`
+	EndTime     int64 `json:"end_time,omitempty"`
+	Granularity int   `json:"granularity,omitempty"`
+}
+
+type HoneycombCalculation struct {
+	Op     string `json:"op"`
+	Column string `json:"column,omitempty"`
+}
+
+type HoneycombFilter struct {
+	Column string `json:"column"`
+	Op     string `json:"op"`
+	Value  any    `json:"value,omitempty"`
+}
+
+type HoneycombOrder struct {
+	Column string `json:"column,omitempty"`
+	Op     string `json:"op,omitempty"`
+	Order  string `json:"order,omitempty"`
+}
+
+type HoneycombHaving struct {
+	CalculateOp string `json:"calculate_op"`
+	Column      string `json:"column,omitempty"`
+	Op          string `json:"op"`
+	Value       any    `json:"value"`
+}
+
+// HoneycombOptions configures converting Honeycomb queries.
+type HoneycombOptions struct {
+	// BaseURL is the base URL of the Datadog link e.g. https://app.datadoghq.com
+	BaseURL string
+	// Target is the kind of link to produce; TargetTraces (the default) or TargetLogs
+	Target string
+	// ColumnMapping maps Honeycomb columns to Datadog fields. It extends DefaultColumnMapping.
+	// Columns that aren't mapped become facets e.g. app.user_id becomes @app.user_id.
+	ColumnMapping map[string]string
+}
+
+// HoneycombURLToLink converts a Honeycomb query URL into a Datadog link. The URL must contain the query
+// specification in the query parameter; links to saved query results can't be converted.
+func HoneycombURLToLink(u string, opts HoneycombOptions) (any, []Warning, error) {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to parse URL: %v", u)
+	}
+
+	spec := parsed.Query().Get("query")
+	if spec == "" {
+		return nil, nil, errors.Errorf("URL %v doesn't have a query parameter containing the query specification", u)
+	}
+
+	q := &HoneycombQuery{}
+	if err := json.Unmarshal([]byte(spec), q); err != nil {
+		return nil, nil, errors.Wrapf(err, "Failed to parse the query specification")
+	}
+
+	link, warnings, err := HoneycombQueryToLink(q, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	parts := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	for i := 0; i+1 < len(parts); i++ {
+		if parts[i] == "datasets" {
+			warnings = append(warnings, warn("dataset "+parts[i+1], "datasets aren't translated; add the service or other tags for the dataset to the query"))
+		}
+	}
+	return link, warnings, nil
+}
+
+// HoneycombQueryToLink converts a Honeycomb query specification into a DatadogTraceSearch or DatadogLink.
+// Only the first supported calculation is translated; the rest and any havings are reported as warnings.
+func HoneycombQueryToLink(q *HoneycombQuery, opts HoneycombOptions) (any, []Warning, error) {
+	c := &honeycombConverter{
+		columns: MergeMappings(DefaultColumnMapping, opts.ColumnMapping),
+	}
+
+	queryString, err := c.convertFilters(q)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var measure, aggType string
+	for _, calc := range q.Calculations {
+		construct := calcString(calc.Op, calc.Column)
+		t, ok := honeycombAggTypes[strings.ToUpper(calc.Op)]
+		switch {
+		case !ok:
+			c.warn(construct, "the calculation isn't supported by Datadog")
+		case aggType != "":
+			c.warn(construct, "only the first calculation is translated")
+		default:
+			aggType = t
+			measure = "count"
+			if calc.Column != "" {
+				measure = c.field(calc.Column)
+			}
+		}
+	}
+
+	for _, h := range q.Havings {
+		c.warn(fmt.Sprintf("having %v %v %v", calcString(h.CalculateOp, h.Column), h.Op, h.Value), "havings aren't supported")
+	}
+	for _, o := range q.Orders {
+		if o.Op == "" || strings.ToLower(o.Order) == "ascending" {
+			c.warn(fmt.Sprintf("order by %v %v", calcString(o.Op, o.Column), o.Order), "Datadog orders groups by the descending value of the calculation")
+		}
+	}
+	if q.Granularity != 0 {
+		c.warn(fmt.Sprintf("granularity %v", q.Granularity), "Datadog picks the interval from the time range")
+	}
+
+	groupBy := make([]string, 0, len(q.Breakdowns))
+	for _, b := range q.Breakdowns {
+		groupBy = append(groupBy, c.field(b))
+	}
+	if len(groupBy) > 0 && aggType == "" {
+		// Honeycomb counts events if there is a breakdown without a calculation
+		measure, aggType = "count", "count"
+	}
+
+	fromTS, toTS := honeycombTimeRange(q)
+	topN := 0
+	if len(groupBy) > 0 {
+		topN = q.Limit
+	}
+
+	switch opts.Target {
+	case "", TargetTraces:
+		link := &api.DatadogTraceSearch{
+			APIVersion: api.TraceSearchGVK.GroupVersion().String(),
+			Kind:       api.TraceSearchGVK.Kind,
+			BaseURL:    opts.BaseURL,
+			Query:      queryString,
+			Measure:    measure,
+			AggType:    aggType,
+			GroupBy:    groupBy,
+			TopN:       topN,
+			FromTS:     fromTS,
+			ToTS:       toTS,
+		}
+		if aggType != "" {
+			link.VisualizeAs = "timeseries"
+		}
+		return link, c.warnings, nil
+	case TargetLogs:
+		link := &api.DatadogLink{
+			APIVersion: api.LinkGVK.GroupVersion().String(),
+			Kind:       api.LinkGVK.Kind,
+			BaseURL:    opts.BaseURL,
+			Query:      queryString,
+			GroupInto:  measure,
+			AggType:    aggType,
+			GroupBy:    strings.Join(groupBy, ","),
+			TopN:       topN,
+			FromTS:     fromTS,
+			ToTS:       toTS,
+		}
+		if aggType != "" {
+			link.VisualizeAs = "timeseries"
+		}
+		return link, c.warnings, nil
+	}
+	return nil, nil, errors.Errorf("Unsupported target %v; must be %v or %v", opts.Target, TargetTraces, TargetLogs)
+}
+
+type honeycombConverter struct {
+	columns  map[string]string
+	warnings []Warning
+}
+
+func (c *honeycombConverter) warn(construct string, format string, args ...any) {
+	c.warnings = append(c.warnings, warn(construct, format, args...))
+}
+
+// field returns the Datadog field for a Honeycomb column.
+func (c *honeycombConverter) field(column string) string {
+	if f, ok := c.columns[column]; ok {
+		return f
+	}
+	return "@" + column
+}
+
+func (c *honeycombConverter) convertFilters(q *HoneycombQuery) (string, error) {
+	b := ddog.Q()
+	var alternatives []*ddog.QueryBuilder
+	for _, f := range q.Filters {
+		n, ok := c.convertFilter(f)
+		if !ok {
+			continue
+		}
+		b.Term(n)
+		alternatives = append(alternatives, ddog.Q().Term(n))
+	}
+
+	switch strings.ToUpper(q.FilterCombination) {
+	case "", "AND":
+		return b.String(), nil
+	case "OR":
+		return ddog.Q().Or(alternatives...).String(), nil
+	}
+	return "", errors.Errorf("Unsupported filter_combination %v", q.FilterCombination)
+}
+
+func (c *honeycombConverter) convertFilter(f HoneycombFilter) (query.Node, bool) {
+	construct := strings.TrimSpace(fmt.Sprintf("%v %v %v", f.Column, f.Op, formatValue(f.Value)))
+	key := c.field(f.Column)
+
+	if f.Column == "error" && (f.Op == "=" || f.Op == "!=") {
+		// Spans with errors have the error status in Datadog
+		isError, _ := strconv.ParseBool(formatValue(f.Value))
+		status := &query.Field{Key: "status", Value: query.Literal("error")}
+		if isError == (f.Op == "=") {
+			return status, true
+		}
+		return &query.Not{Operand: status}, true
+	}
+
+	negate := false
+	var value query.Node
+	switch op := strings.ToLower(f.Op); op {
+	case "=", "!=":
+		value = query.Literal(c.scale(f.Column, f.Value))
+		negate = op == "!="
+	case ">", ">=", "<", "<=":
+		v := c.scale(f.Column, f.Value)
+		if _, err := strconv.ParseFloat(v, 64); err != nil {
+			c.warn(construct, "only numeric comparisons are supported")
+			return nil, false
+		}
+		value = &query.Comparison{Op: op, Value: v}
+	case "starts-with", "does-not-start-with":
+		value = query.Wildcard(formatValue(f.Value) + "*")
+		negate = strings.HasPrefix(op, "does-not")
+	case "ends-with", "does-not-end-with":
+		value = query.Wildcard("*" + formatValue(f.Value))
+		negate = strings.HasPrefix(op, "does-not")
+	case "contains", "does-not-contain":
+		value = query.Wildcard("*" + formatValue(f.Value) + "*")
+		negate = strings.HasPrefix(op, "does-not")
+	case "exists", "does-not-exist":
+		value = &query.Text{Value: "*"}
+		negate = op == "does-not-exist"
+	case "in", "not-in":
+		values, ok := f.Value.([]any)
+		if !ok || len(values) == 0 {
+			c.warn(construct, "expected a list of values")
+			return nil, false
+		}
+		operands := make([]query.Node, 0, len(values))
+		for _, v := range values {
+			operands = append(operands, query.Literal(c.scale(f.Column, v)))
+		}
+		value = &query.Group{Expr: &query.Or{Operands: operands}}
+		if len(operands) == 1 {
+			value = operands[0]
+		}
+		negate = op == "not-in"
+	default:
+		c.warn(construct, "the filter operator isn't supported")
+		return nil, false
+	}
+
+	var n query.Node = &query.Field{Key: key, Value: value}
+	if negate {
+		n = &query.Not{Operand: n}
+	}
+	return n, true
+}
+
+// scale converts the value of a column into the unit of the Datadog field. Values are only scaled if the column
+// is mapped to its default field.
+func (c *honeycombConverter) scale(column string, value any) string {
+	s := formatValue(value)
+	factor, ok := columnScales[column]
+	if !ok || c.field(column) != DefaultColumnMapping[column] {
+		return s
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return s
+	}
+	return strconv.FormatFloat(f*factor, 'f', -1, 64)
+}
+
+func formatValue(v any) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+func calcString(op string, column string) string {
+	if column == "" {
+		return op
+	}
+	return fmt.Sprintf("%v(%v)", op, column)
+}
+
+// honeycombTimeRange returns the time range of the query as Datadog link timestamps.
+func honeycombTimeRange(q *HoneycombQuery) (string, string) {
+	toMillis := func(secs int64) string {
+		return strconv.FormatInt(secs*1000, 10)
+	}
+
+	switch {
+	case q.StartTime != 0 && q.EndTime != 0:
+		return toMillis(q.StartTime), toMillis(q.EndTime)
+	case q.StartTime != 0 && q.TimeRange != 0:
+		return toMillis(q.StartTime), toMillis(q.StartTime + int64(q.TimeRange))
+	case q.EndTime != 0 && q.TimeRange != 0:
+		return toMillis(q.EndTime - int64(q.TimeRange)), toMillis(q.EndTime)
+	case q.TimeRange != 0:
+		return fmt.Sprintf("now-%ds", q.TimeRange), "now"
+	}
+	return "", ""
+}
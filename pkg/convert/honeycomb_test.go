@@ -0,0 +1,88 @@
+package convert
+
+import (
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jlewi/ddctl/api"
+)
+
+func TestHoneycombQueryToLink(t *testing.T) {
+	raw, err := os.ReadFile(filepath.Join("test_data", "honeycomb_query.json"))
+	if err != nil {
+		t.Fatalf("Failed to read test data: %v", err)
+	}
+
+	u := "https://ui.honeycomb.io/acme/environments/prod/datasets/feserver?query=" + url.QueryEscape(string(raw))
+	actual, warnings, err := HoneycombURLToLink(u, HoneycombOptions{BaseURL: "https://acme.datadoghq.com"})
+	if err != nil {
+		t.Fatalf("Failed to convert query: %+v", err)
+	}
+
+	expected := &api.DatadogTraceSearch{
+		APIVersion:  api.TraceSearchGVK.GroupVersion().String(),
+		Kind:        api.TraceSearchGVK.Kind,
+		BaseURL:     "https://acme.datadoghq.com",
+		Query:       `service:feserver @duration:>250000000 @http.route:\/api\/* status:error @app.tenant:(acme OR globex)`,
+		VisualizeAs: "timeseries",
+		Measure:     "@duration",
+		AggType:     "pc99",
+		GroupBy:     []string{"resource_name"},
+		TopN:        20,
+		FromTS:      "now-7200s",
+		ToTS:        "now",
+	}
+	if d := cmp.Diff(expected, actual); d != "" {
+		t.Errorf("Link doesn't match; diff\n%v", d)
+	}
+
+	var actualWarnings []string
+	for _, w := range warnings {
+		actualWarnings = append(actualWarnings, w.String())
+	}
+	expectedWarnings := []string{
+		"db.statement matches SELECT.*: the filter operator isn't supported",
+		"HEATMAP(duration_ms): the calculation isn't supported by Datadog",
+		"COUNT: only the first calculation is translated",
+		"having COUNT > 10: havings aren't supported",
+		"dataset feserver: datasets aren't translated; add the service or other tags for the dataset to the query",
+	}
+	if d := cmp.Diff(expectedWarnings, actualWarnings); d != "" {
+		t.Errorf("Warnings don't match; diff\n%v", d)
+	}
+}
+
+func TestHoneycombQueryToLink_Logs(t *testing.T) {
+	q := &HoneycombQuery{}
+	spec := `{"filters": [{"column": "service.name", "op": "=", "value": "feserver"}, {"column": "level", "op": "=", "value": "error"}], "filter_combination": "OR", "breakdowns": ["host.name"], "start_time": 1736927929, "end_time": 1736949529}`
+	if err := json.Unmarshal([]byte(spec), q); err != nil {
+		t.Fatalf("Failed to unmarshal query: %v", err)
+	}
+
+	actual, warnings, err := HoneycombQueryToLink(q, HoneycombOptions{Target: TargetLogs, ColumnMapping: map[string]string{"level": "status"}})
+	if err != nil {
+		t.Fatalf("Failed to convert query: %+v", err)
+	}
+
+	expected := &api.DatadogLink{
+		APIVersion:  api.LinkGVK.GroupVersion().String(),
+		Kind:        api.LinkGVK.Kind,
+		Query:       "service:feserver OR status:error",
+		VisualizeAs: "timeseries",
+		GroupInto:   "count",
+		AggType:     "count",
+		GroupBy:     "host",
+		FromTS:      "1736927929000",
+		ToTS:        "1736949529000",
+	}
+	if d := cmp.Diff(expected, actual); d != "" {
+		t.Errorf("Link doesn't match; diff\n%v", d)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("Expected no warnings but got %v", warnings)
+	}
+}
@@ -0,0 +1,326 @@
+package convert
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jlewi/ddctl/api"
+	"github.com/jlewi/ddctl/pkg/ddog"
+	"github.com/jlewi/ddctl/pkg/query"
+	"github.com/pkg/errors"
+)
+
+const (
+	kqlLanguage    = "kuery"
+	luceneLanguage = "lucene"
+)
+
+// KibanaOptions configures converting Kibana links.
+type KibanaOptions struct {
+	// BaseURL is the base URL of the Datadog link e.g. https://app.datadoghq.com
+	BaseURL string
+	// FieldMapping maps Elasticsearch fields to Datadog fields. It extends DefaultKibanaFieldMapping.
+	// Fields that aren't mapped become facets e.g. user.id becomes @user.id.
+	FieldMapping map[string]string
+}
+
+// KibanaURLToLink converts a Kibana Discover URL into a DatadogLink.
+//
+// The rison encoded _g, _a and _q state of the URL provide the time window, the KQL or Lucene query, the filters
+// and the selected columns. Filters are ANDed with the query; disabled filters are ignored. Data views, saved
+// searches and custom filters aren't translated and are reported as warnings.
+func KibanaURLToLink(u string, opts KibanaOptions) (*api.DatadogLink, []Warning, error) {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "Failed to parse URL %v", u)
+	}
+
+	// The state is in the fragment e.g. /app/discover#/?_g=(...)&_a=(...)
+	params := parsed.Query()
+	fragmentPath, fragmentQuery, _ := strings.Cut(parsed.Fragment, "?")
+	if fragmentQuery != "" {
+		fragmentParams, err := url.ParseQuery(fragmentQuery)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "Failed to parse the fragment of URL %v", u)
+		}
+		for k, v := range fragmentParams {
+			params[k] = v
+		}
+	}
+
+	c := &kibanaConverter{
+		fields: MergeMappings(DefaultKibanaFieldMapping, opts.FieldMapping),
+		b:      ddog.Q(),
+		link: &api.DatadogLink{
+			APIVersion: api.LinkGVK.GroupVersion().String(),
+			Kind:       api.LinkGVK.Kind,
+			BaseURL:    opts.BaseURL,
+		},
+	}
+
+	if strings.HasPrefix(fragmentPath, "/view/") {
+		c.warnings = append(c.warnings, warn(fragmentPath, "saved searches aren't translated; only the state in the URL is converted"))
+	}
+
+	state := map[string]map[string]any{}
+	for _, key := range []string{"_g", "_a", "_q"} {
+		raw := params.Get(key)
+		if raw == "" {
+			continue
+		}
+		v, err := decodeRison(raw)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "Failed to decode %v", key)
+		}
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil, nil, errors.Errorf("Expected %v to be an object but got %v", key, raw)
+		}
+		state[key] = obj
+	}
+
+	if len(state) == 0 {
+		return nil, nil, errors.Errorf("The URL doesn't have any Discover state (_g, _a or _q); %v", u)
+	}
+
+	c.convertTime(state["_g"])
+
+	for _, key := range []string{"_a", "_q"} {
+		if q, ok := state[key]["query"].(map[string]any); ok {
+			if err := c.convertQuery(q); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	for _, key := range []string{"_g", "_a", "_q"} {
+		filters, _ := state[key]["filters"].([]any)
+		for _, f := range filters {
+			c.convertFilter(f)
+		}
+	}
+
+	if columns, ok := state["_a"]["columns"].([]any); ok {
+		for _, col := range columns {
+			name, _ := col.(string)
+			if column := c.fields.column(name); column != "" {
+				c.link.Columns = append(c.link.Columns, column)
+			}
+		}
+	}
+
+	if index, ok := state["_a"]["index"].(string); ok {
+		c.warnings = append(c.warnings, warn("index "+index, "data views aren't translated; add the indexes or tags for the data view to the query"))
+	}
+	c.convertSort(state["_a"]["sort"])
+
+	c.link.Query = c.b.String()
+	return c.link, c.warnings, nil
+}
+
+type kibanaConverter struct {
+	fields   kibanaFields
+	b        *ddog.QueryBuilder
+	link     *api.DatadogLink
+	warnings []Warning
+}
+
+// convertTime converts the time window in the global state e.g. time:(from:now-15m,to:now).
+func (c *kibanaConverter) convertTime(g map[string]any) {
+	t, ok := g["time"].(map[string]any)
+	if !ok {
+		return
+	}
+	from, _ := t["from"].(string)
+	to, _ := t["to"].(string)
+	c.link.FromTS = c.convertTimestamp("from", from)
+	c.link.ToTS = c.convertTimestamp("to", to)
+}
+
+// convertTimestamp converts a Kibana date math expression (e.g. now-15m) or an ISO 8601 timestamp.
+func (c *kibanaConverter) convertTimestamp(name string, value string) string {
+	if value == "" {
+		return ""
+	}
+	if strings.HasPrefix(value, "now") {
+		if i := strings.Index(value, "/"); i >= 0 {
+			c.warnings = append(c.warnings, warn(name+":"+value, "rounding to %v isn't supported", value[i:]))
+			value = value[:i]
+		}
+		return value
+	}
+
+	ts, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		c.warnings = append(c.warnings, warn(name+":"+value, "only date math relative to now and ISO 8601 timestamps are supported"))
+		return ""
+	}
+	return strconv.FormatInt(ts.UnixMilli(), 10)
+}
+
+// convertQuery converts the query in the app state e.g. query:(language:kuery,query:'status:500').
+func (c *kibanaConverter) convertQuery(q map[string]any) error {
+	text, _ := q["query"].(string)
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+
+	var result *KQLResult
+	var err error
+	switch language, _ := q["language"].(string); language {
+	case kqlLanguage, "":
+		result, err = KQLToQuery(text, c.fields)
+	case luceneLanguage:
+		result, err = LuceneToQuery(text, c.fields)
+	default:
+		return errors.Errorf("Unsupported query language %v; only kuery and lucene are supported", language)
+	}
+	if err != nil {
+		return err
+	}
+
+	c.warnings = append(c.warnings, result.Warnings...)
+	if result.Query == "" {
+		return nil
+	}
+	n, err := query.Parse(result.Query)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to parse translated query %v", result.Query)
+	}
+	c.b.Term(n)
+	return nil
+}
+
+// convertFilter converts a filter pill e.g. (meta:(key:status,negate:!f,params:(query:'500'),type:phrase),...).
+func (c *kibanaConverter) convertFilter(f any) {
+	filter, _ := f.(map[string]any)
+	meta, _ := filter["meta"].(map[string]any)
+	if meta == nil {
+		c.warnings = append(c.warnings, warn("filter", "filters without meta aren't supported"))
+		return
+	}
+	if disabled, _ := meta["disabled"].(bool); disabled {
+		return
+	}
+
+	key, _ := meta["key"].(string)
+	filterType, _ := meta["type"].(string)
+	construct := fmt.Sprintf("filter %v %v", filterType, key)
+	if key == "" {
+		c.warnings = append(c.warnings, warn(construct, "the filter doesn't have a field"))
+		return
+	}
+
+	var n query.Node
+	switch filterType {
+	case "phrase":
+		params, _ := meta["params"].(map[string]any)
+		n = c.fields.match(key, query.Literal(risonString(params["query"])))
+	case "phrases":
+		params, _ := meta["params"].([]any)
+		values := make([]query.Node, 0, len(params))
+		for _, p := range params {
+			values = append(values, query.Literal(risonString(p)))
+		}
+		switch len(values) {
+		case 0:
+			c.warnings = append(c.warnings, warn(construct, "the filter doesn't have any values"))
+			return
+		case 1:
+			n = c.fields.match(key, values[0])
+		default:
+			n = c.fields.match(key, &query.Group{Expr: &query.Or{Operands: values}})
+		}
+	case "exists":
+		n = &query.Field{Key: c.fields.key(key), Value: &query.Text{Value: "*"}}
+	case "range":
+		params, _ := meta["params"].(map[string]any)
+		n = c.rangeFilter(construct, key, params)
+	default:
+		c.warnings = append(c.warnings, warn(construct, "the filter type isn't supported"))
+	}
+	if n == nil {
+		return
+	}
+
+	if negate, _ := meta["negate"].(bool); negate {
+		n = &query.Not{Operand: n}
+	}
+	c.b.Term(n)
+}
+
+// rangeFilter converts the params of a range filter e.g. (gte:100,lt:200).
+func (c *kibanaConverter) rangeFilter(construct string, key string, params map[string]any) query.Node {
+	gte, hasGte := params["gte"]
+	gt, hasGt := params["gt"]
+	lte, hasLte := params["lte"]
+	lt, hasLt := params["lt"]
+
+	r := &query.Range{Low: "*", High: "*"}
+	var comparisons []query.Node
+	switch {
+	case hasGte:
+		r.Low = risonString(gte)
+		comparisons = append(comparisons, &query.Comparison{Op: ">=", Value: r.Low})
+	case hasGt:
+		r.Low = risonString(gt)
+		r.ExclusiveLow = true
+		comparisons = append(comparisons, &query.Comparison{Op: ">", Value: r.Low})
+	}
+	switch {
+	case hasLte:
+		r.High = risonString(lte)
+		comparisons = append(comparisons, &query.Comparison{Op: "<=", Value: r.High})
+	case hasLt:
+		r.High = risonString(lt)
+		r.ExclusiveHigh = true
+		comparisons = append(comparisons, &query.Comparison{Op: "<", Value: r.High})
+	}
+
+	switch len(comparisons) {
+	case 0:
+		c.warnings = append(c.warnings, warn(construct, "the range doesn't have any bounds"))
+		return nil
+	case 1:
+		return c.fields.match(key, comparisons[0])
+	}
+	return c.fields.match(key, r)
+}
+
+// convertSort warns about sorting on anything other than the timestamp which Datadog doesn't support.
+func (c *kibanaConverter) convertSort(sortState any) {
+	sorts, _ := sortState.([]any)
+	var fields []string
+	for _, s := range sorts {
+		pair, _ := s.([]any)
+		if len(pair) == 0 {
+			continue
+		}
+		if field := risonString(pair[0]); field != "@timestamp" {
+			fields = append(fields, field)
+		}
+	}
+	if len(fields) > 0 {
+		sort.Strings(fields)
+		c.warnings = append(c.warnings, warn("sort "+strings.Join(fields, ","), "sorting on fields other than the timestamp isn't supported"))
+	}
+}
+
+// risonString returns the string for a decoded rison scalar.
+func risonString(v any) string {
+	switch s := v.(type) {
+	case string:
+		return s
+	case float64:
+		return strconv.FormatFloat(s, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(s)
+	case nil:
+		return ""
+	}
+	return fmt.Sprintf("%v", v)
+}
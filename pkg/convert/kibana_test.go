@@ -0,0 +1,101 @@
+package convert
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jlewi/ddctl/api"
+)
+
+func TestKibanaURLToLink(t *testing.T) {
+	type testCase struct {
+		Name             string
+		Input            string
+		Expected         *api.DatadogLink
+		ExpectedWarnings []string
+	}
+
+	cases := []testCase{
+		{
+			Name: "kql",
+			Input: "https://kibana.acme.com/app/discover#/?_g=(filters:!(),refreshInterval:(pause:!t,value:0),time:(from:now-7d%2Fd,to:now))" +
+				"&_a=(columns:!(message,host.name,user.id),filters:!(" +
+				"(meta:(disabled:!f,key:log.level,negate:!t,params:(query:debug),type:phrase),query:(match_phrase:(log.level:debug)))," +
+				"(meta:(disabled:!f,key:http.response.status_code,negate:!f,params:(gte:500,lt:600),type:range),query:(range:(http.response.status_code:(gte:500,lt:600))))," +
+				"(meta:(disabled:!t,key:env,negate:!f,params:(query:prod),type:phrase),query:(match_phrase:(env:prod)))," +
+				"(meta:(disabled:!f,key:kubernetes.namespace,negate:!f,params:!(shop,payments),type:phrases),query:(bool:(should:!()))))," +
+				"index:'logs-*',interval:auto,query:(language:kuery,query:'service.name%20:%20%22checkout%22%20and%20not%20message:%22health%20check%22')," +
+				"sort:!(!('@timestamp',desc)))",
+			Expected: &api.DatadogLink{
+				Query:   `-"health check" service:checkout -status:debug @http.status_code:[500 TO 600} kube_namespace:(shop OR payments)`,
+				FromTS:  "now-7d",
+				ToTS:    "now",
+				Columns: []string{"host", "@user.id"},
+			},
+			ExpectedWarnings: []string{
+				"from:now-7d/d: rounding to /d isn't supported",
+				"index logs-*: data views aren't translated; add the indexes or tags for the data view to the query",
+			},
+		},
+		{
+			Name: "lucene",
+			Input: "https://kibana.acme.com/app/kibana#/discover?_g=(time:(from:'2025-01-15T07:58:49.003Z',to:'2025-01-15T13:58:49.003Z'))" +
+				"&_a=(columns:!(_source),filters:!((meta:(key:user.id,type:exists),query:(exists:(field:user.id)))," +
+				"(meta:(key:query,type:custom),query:(bool:(must:!())))),query:(language:lucene,query:'status:500%20timeout'),sort:!(!(duration,desc)))",
+			Expected: &api.DatadogLink{
+				Query:  "(@status:500 OR timeout) @user.id:*",
+				FromTS: "1736927929003",
+				ToTS:   "1736949529003",
+			},
+			ExpectedWarnings: []string{
+				"filter custom query: the filter type isn't supported",
+				"sort duration: sorting on fields other than the timestamp isn't supported",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			actual, warnings, err := KibanaURLToLink(c.Input, KibanaOptions{BaseURL: "https://acme.datadoghq.com"})
+			if err != nil {
+				t.Fatalf("Failed to convert %v: %+v", c.Input, err)
+			}
+
+			c.Expected.APIVersion = api.LinkGVK.GroupVersion().String()
+			c.Expected.Kind = api.LinkGVK.Kind
+			c.Expected.BaseURL = "https://acme.datadoghq.com"
+			if d := cmp.Diff(c.Expected, actual); d != "" {
+				t.Errorf("Link doesn't match; diff\n%v", d)
+			}
+
+			var actualWarnings []string
+			for _, w := range warnings {
+				actualWarnings = append(actualWarnings, w.String())
+			}
+			if d := cmp.Diff(c.ExpectedWarnings, actualWarnings); d != "" {
+				t.Errorf("Warnings don't match; diff\n%v", d)
+			}
+		})
+	}
+}
+
+func TestDecodeRison(t *testing.T) {
+	actual, err := decodeRison(`(a:!(1,-2.5,!t,!n),b:'it!'s',c:(d:'',e:x.y))`)
+	if err != nil {
+		t.Fatalf("Failed to decode rison: %+v", err)
+	}
+	expected := map[string]any{
+		"a": []any{1.0, -2.5, true, nil},
+		"b": "it's",
+		"c": map[string]any{"d": "", "e": "x.y"},
+	}
+	if d := cmp.Diff(expected, actual); d != "" {
+		t.Errorf("Value doesn't match; diff\n%v", d)
+	}
+
+	for _, bad := range []string{`(a:1`, `!(1 2)`, `'abc`, `(a:!x)`} {
+		if _, err := decodeRison(bad); err == nil {
+			t.Errorf("Expected an error decoding %v", bad)
+		}
+	}
+}
@@ -0,0 +1,479 @@
+package convert
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/jlewi/ddctl/pkg/query"
+	"github.com/pkg/errors"
+)
+
+const (
+	// kibanaMessageField is the field containing the log message. Matching it becomes free text in Datadog.
+	kibanaMessageField = "message"
+)
+
+// DefaultKibanaFieldMapping maps Elastic Common Schema fields to the equivalent Datadog tags and attributes.
+var DefaultKibanaFieldMapping = map[string]string{
+	"service.name":              "service",
+	"service.environment":       "env",
+	"host.name":                 "host",
+	"host.hostname":             "host",
+	"log.level":                 "status",
+	"kubernetes.namespace":      "kube_namespace",
+	"kubernetes.pod.name":       "pod_name",
+	"kubernetes.container.name": "container_name",
+	"container.name":            "container_name",
+	"trace.id":                  "trace_id",
+	"http.request.method":       "@http.method",
+	"http.response.status_code": "@http.status_code",
+	"url.path":                  "@http.url_details.path",
+}
+
+// KQLResult is the Datadog query translated from a KQL or Lucene query.
+type KQLResult struct {
+	// Query is the Datadog search query
+	Query    string
+	Warnings []Warning
+}
+
+// kibanaFields maps Elasticsearch fields to Datadog fields.
+type kibanaFields map[string]string
+
+func (f kibanaFields) key(name string) string {
+	if key, ok := f[name]; ok {
+		return key
+	}
+	return "@" + name
+}
+
+// match returns the node matching a value of the field. Matching the message field becomes free text.
+func (f kibanaFields) match(name string, value query.Node) query.Node {
+	if name == kibanaMessageField {
+		return value
+	}
+	return &query.Field{Key: f.key(name), Value: value}
+}
+
+// column returns the Datadog column for a field or "" if Datadog always displays it.
+func (f kibanaFields) column(name string) string {
+	switch name {
+	case "_source", "@timestamp", kibanaMessageField:
+		return ""
+	}
+	return f.key(name)
+}
+
+// KQLToQuery translates a Kibana Query Language query into a Datadog search query.
+//
+// Field matches, phrases, wildcards, exists (field:*), ranges (>, >=, <, <=), and, or, not and parentheses
+// are supported. Nested field queries e.g. items:{ name:x } are reported as warnings.
+func KQLToQuery(kql string, fieldMapping map[string]string) (*KQLResult, error) {
+	p := &kqlParser{
+		src:    kql,
+		fields: MergeMappings(DefaultKibanaFieldMapping, fieldMapping),
+		result: &KQLResult{},
+	}
+
+	p.skipSpace()
+	var n query.Node
+	if !p.eof() {
+		var err error
+		n, err = p.parseOr(false)
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if !p.eof() {
+			return nil, p.errorf("unexpected %q", p.peek())
+		}
+	}
+
+	if n != nil {
+		p.result.Query = query.Print(query.Normalize(n))
+	}
+	return p.result, nil
+}
+
+type kqlParser struct {
+	src    string
+	pos    int
+	fields kibanaFields
+	result *KQLResult
+}
+
+func (p *kqlParser) errorf(format string, args ...any) error {
+	return errors.Errorf("invalid KQL at column %d: %v", p.pos+1, errors.Errorf(format, args...))
+}
+
+func (p *kqlParser) warn(start int, format string, args ...any) {
+	construct := strings.TrimSpace(p.src[start:p.pos])
+	p.result.Warnings = append(p.result.Warnings, warnAt(p.src, start, construct, format, args...))
+}
+
+func (p *kqlParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *kqlParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *kqlParser) skipSpace() {
+	for !p.eof() && strings.ContainsRune(" \t\n\r", rune(p.peek())) {
+		p.pos++
+	}
+}
+
+// peekKeyword returns true if the case insensitive keyword is next and is followed by a delimiter.
+func (p *kqlParser) peekKeyword(kw string) bool {
+	p.skipSpace()
+	end := p.pos + len(kw)
+	if end > len(p.src) || !strings.EqualFold(p.src[p.pos:end], kw) {
+		return false
+	}
+	return end == len(p.src) || strings.ContainsRune(" \t\n\r()\"", rune(p.src[end]))
+}
+
+// atEnd returns true if there are no more terms in the current expression.
+func (p *kqlParser) atEnd() bool {
+	p.skipSpace()
+	return p.eof() || p.peek() == ')' || p.peek() == '}'
+}
+
+// parseOr parses an expression. If inValue is true the expression is the values of a field e.g. (a or b).
+func (p *kqlParser) parseOr(inValue bool) (query.Node, error) {
+	var operands []query.Node
+	for {
+		n, err := p.parseAnd(inValue)
+		if err != nil {
+			return nil, err
+		}
+		if n != nil {
+			operands = append(operands, n)
+		}
+		if !p.peekKeyword("or") {
+			break
+		}
+		p.pos += len("or")
+		if p.atEnd() {
+			return nil, p.errorf("expected a term after or")
+		}
+	}
+
+	switch len(operands) {
+	case 0:
+		return nil, nil
+	case 1:
+		return operands[0], nil
+	}
+	return &query.Or{Operands: operands}, nil
+}
+
+func (p *kqlParser) parseAnd(inValue bool) (query.Node, error) {
+	var operands []query.Node
+	for !p.atEnd() && !p.peekKeyword("or") {
+		if p.peekKeyword("and") {
+			p.pos += len("and")
+			if p.atEnd() {
+				return nil, p.errorf("expected a term after and")
+			}
+			continue
+		}
+
+		n, err := p.parseNot(inValue)
+		if err != nil {
+			return nil, err
+		}
+		if n != nil {
+			operands = append(operands, n)
+		}
+	}
+
+	switch len(operands) {
+	case 0:
+		return nil, nil
+	case 1:
+		return operands[0], nil
+	}
+	return &query.And{Operands: operands, Implicit: true}, nil
+}
+
+func (p *kqlParser) parseNot(inValue bool) (query.Node, error) {
+	if p.peekKeyword("not") {
+		p.pos += len("not")
+		if p.atEnd() {
+			return nil, p.errorf("expected a term after not")
+		}
+		n, err := p.parseNot(inValue)
+		if err != nil || n == nil {
+			return nil, err
+		}
+		return &query.Not{Operand: n}, nil
+	}
+	return p.parsePrimary(inValue)
+}
+
+func (p *kqlParser) parsePrimary(inValue bool) (query.Node, error) {
+	p.skipSpace()
+	start := p.pos
+	if p.peek() == '(' {
+		p.pos++
+		n, err := p.parseOr(inValue)
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if p.peek() != ')' {
+			p.pos = start
+			return nil, p.errorf("missing ) to close (")
+		}
+		p.pos++
+		return n, nil
+	}
+
+	value, quoted, err := p.readValue()
+	if err != nil {
+		return nil, err
+	}
+	if inValue || quoted {
+		return kqlValue(value, quoted), nil
+	}
+
+	p.skipSpace()
+	switch {
+	case p.peek() == ':':
+		p.pos++
+		return p.parseFieldValue(start, value)
+	case p.peek() == '<' || p.peek() == '>':
+		op := string(p.peek())
+		p.pos++
+		if p.peek() == '=' {
+			op += "="
+			p.pos++
+		}
+		bound, _, err := p.readValue()
+		if err != nil {
+			return nil, err
+		}
+		return p.fields.match(value, &query.Comparison{Op: op, Value: bound}), nil
+	}
+	return kqlValue(value, false), nil
+}
+
+// parseFieldValue parses the value after field: e.g. the value of status:(500 or 502).
+func (p *kqlParser) parseFieldValue(start int, field string) (query.Node, error) {
+	p.skipSpace()
+	switch p.peek() {
+	case '{':
+		depth := 0
+		for ; !p.eof(); p.pos++ {
+			if p.peek() == '{' {
+				depth++
+			}
+			if p.peek() == '}' {
+				depth--
+				if depth == 0 {
+					p.pos++
+					break
+				}
+			}
+		}
+		p.warn(start, "nested field queries aren't supported")
+		return nil, nil
+	case '(':
+		p.pos++
+		n, err := p.parseOr(true)
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if p.peek() != ')' {
+			return nil, p.errorf("missing ) to close the values of %v", field)
+		}
+		p.pos++
+		if n == nil {
+			return nil, nil
+		}
+		return p.fields.match(field, &query.Group{Expr: n}), nil
+	}
+
+	n, err := p.parsePrimary(true)
+	if err != nil {
+		return nil, err
+	}
+	return p.fields.match(field, n), nil
+}
+
+// readValue reads a quoted phrase or an unquoted value. Escape sequences are removed from unquoted values
+// except for escaped wildcards.
+func (p *kqlParser) readValue() (string, bool, error) {
+	p.skipSpace()
+	start := p.pos
+	if p.peek() == '"' {
+		for p.pos++; !p.eof() && p.peek() != '"'; p.pos++ {
+			if p.peek() == '\\' {
+				p.pos++
+			}
+		}
+		if p.eof() {
+			p.pos = start
+			return "", false, p.errorf("unterminated quoted phrase")
+		}
+		p.pos++
+		value, err := strconv.Unquote(p.src[start:p.pos])
+		if err != nil {
+			// KQL allows escapes that Go doesn't
+			value = strings.ReplaceAll(p.src[start+1:p.pos-1], `\"`, `"`)
+		}
+		return value, true, nil
+	}
+
+	var sb strings.Builder
+	for !p.eof() {
+		c := p.peek()
+		if strings.ContainsRune(" \t\n\r()\":<>{}", rune(c)) {
+			break
+		}
+		if c == '\\' && p.pos+1 < len(p.src) {
+			p.pos++
+			c = p.peek()
+			if c == '*' {
+				sb.WriteByte('\\')
+			}
+		}
+		sb.WriteByte(c)
+		p.pos++
+	}
+	if sb.Len() == 0 {
+		return "", false, p.errorf("unexpected %q; expected a value", p.peek())
+	}
+	return sb.String(), false, nil
+}
+
+// kqlValue returns the Datadog value for a KQL value. Unquoted values can contain * wildcards.
+func kqlValue(value string, quoted bool) *query.Text {
+	if quoted || !strings.Contains(value, "*") {
+		return query.Literal(strings.ReplaceAll(value, `\*`, "*"))
+	}
+
+	// Split on escaped wildcards so they match literally
+	parts := strings.Split(value, `\*`)
+	var sb strings.Builder
+	for i, part := range parts {
+		if i > 0 {
+			sb.WriteString(`\*`)
+		}
+		sb.WriteString(query.Wildcard(part).Value)
+	}
+	return &query.Text{Value: sb.String()}
+}
+
+// LuceneToQuery translates a Lucene query from Kibana into a Datadog search query. The syntax is close to the
+// Datadog syntax so the query is parsed as a Datadog query and its fields are mapped. Terms separated by
+// whitespace are ORed as they are by Kibana unless they are prefixed with + or -. Terms without a prefix are dropped
+// if any term is prefixed with + because, as in Lucene, they only affect scoring. _exists_:field becomes field:*.
+func LuceneToQuery(lucene string, fieldMapping map[string]string) (*KQLResult, error) {
+	n, err := query.Parse(lucene)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid Lucene query")
+	}
+	fields := kibanaFields(MergeMappings(DefaultKibanaFieldMapping, fieldMapping))
+	result := &KQLResult{}
+	if n != nil {
+		result.Query = query.Print(query.Normalize(luceneToNode(n, fields, lucene, result)))
+	}
+	return result, nil
+}
+
+// isLuceneRequired returns true if the term is prefixed with + e.g. +status:error.
+func isLuceneRequired(n query.Node) bool {
+	switch v := n.(type) {
+	case *query.Text:
+		return !v.Quoted && strings.HasPrefix(v.Value, "+")
+	case *query.Field:
+		return strings.HasPrefix(v.Key, "+")
+	}
+	return false
+}
+
+func luceneToNode(n query.Node, fields kibanaFields, src string, result *KQLResult) query.Node {
+	switch v := n.(type) {
+	case *query.Text:
+		if strings.HasPrefix(v.Value, "+") && !v.Quoted {
+			return &query.Text{Value: v.Value[1:]}
+		}
+		if strings.HasPrefix(v.Value, "/") && !v.Quoted {
+			result.Warnings = append(result.Warnings, warnAt(src, v.Pos(), src[v.Pos():v.End()], "regular expressions aren't supported"))
+		}
+		return v
+	case *query.Field:
+		name := strings.TrimPrefix(v.Key, "+")
+		if name == "_exists_" {
+			if t, ok := v.Value.(*query.Text); ok {
+				return &query.Field{Key: fields.key(t.Value), Value: &query.Text{Value: "*"}}
+			}
+		}
+		if t, ok := v.Value.(*query.Text); ok && strings.HasPrefix(t.Value, "/") && !t.Quoted {
+			result.Warnings = append(result.Warnings, warnAt(src, v.Pos(), src[v.Pos():v.End()], "regular expressions aren't supported"))
+		}
+		return fields.match(name, v.Value)
+	case *query.Not:
+		v.Operand = luceneToNode(v.Operand, fields, src, result)
+		return v
+	case *query.Group:
+		v.Expr = luceneToNode(v.Expr, fields, src, result)
+		return v
+	case *query.And:
+		if !v.Implicit {
+			for i, o := range v.Operands {
+				v.Operands[i] = luceneToNode(o, fields, src, result)
+			}
+			return v
+		}
+
+		// Kibana's default operator is OR so only +required and -prohibited terms must match. When there are
+		// +required terms the optional terms only affect scoring so they are dropped.
+		var required, optional []query.Node
+		hasRequired := false
+		for _, o := range v.Operands {
+			_, isNot := o.(*query.Not)
+			isPlus := isLuceneRequired(o)
+			hasRequired = hasRequired || isPlus
+			if !isNot && !isPlus {
+				optional = append(optional, o)
+				continue
+			}
+			required = append(required, luceneToNode(o, fields, src, result))
+		}
+		if hasRequired {
+			for _, o := range optional {
+				result.Warnings = append(result.Warnings, warnAt(src, o.Pos(), src[o.Pos():o.End()], "optional terms only affect scoring when there are +required terms; dropped"))
+			}
+			optional = nil
+		}
+		for i, o := range optional {
+			optional[i] = luceneToNode(o, fields, src, result)
+		}
+		switch len(optional) {
+		case 0:
+		case 1:
+			required = append(required, optional[0])
+		default:
+			required = append(required, &query.Or{Operands: optional})
+		}
+		if len(required) == 1 {
+			return required[0]
+		}
+		return &query.And{Span: v.Span, Operands: required, Implicit: true}
+	case *query.Or:
+		for i, o := range v.Operands {
+			v.Operands[i] = luceneToNode(o, fields, src, result)
+		}
+		return v
+	}
+	return n
+}
@@ -0,0 +1,121 @@
+package convert
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestKQLToQuery(t *testing.T) {
+	type testCase struct {
+		Name             string
+		Input            string
+		Expected         string
+		ExpectedWarnings []string
+	}
+
+	cases := []testCase{
+		{
+			Name:     "fields",
+			Input:    `service.name : "checkout" and http.response.status_code >= 500 and not host.name: web-01`,
+			Expected: "-host:web-01 service:checkout @http.status_code:>=500",
+		},
+		{
+			Name:     "values",
+			Input:    `log.level:(error or warn) AND user.email:*@acme.com OR trace.id:*`,
+			Expected: "status:(error OR warn) @user.email:*@acme.com OR trace_id:*",
+		},
+		{
+			Name:     "message",
+			Input:    `message:"connection reset" and timeout`,
+			Expected: `"connection reset" timeout`,
+		},
+		{
+			Name:     "nested",
+			Input:    `service.name:api and items:{ name:foo and price > 10 }`,
+			Expected: "service:api",
+			ExpectedWarnings: []string{
+				"column 22: items:{ name:foo and price > 10 }: nested field queries aren't supported",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			actual, err := KQLToQuery(c.Input, nil)
+			if err != nil {
+				t.Fatalf("Failed to convert %v: %+v", c.Input, err)
+			}
+			if actual.Query != c.Expected {
+				t.Errorf("Query doesn't match; got %v; want %v", actual.Query, c.Expected)
+			}
+
+			var actualWarnings []string
+			for _, w := range actual.Warnings {
+				actualWarnings = append(actualWarnings, w.String())
+			}
+			if d := cmp.Diff(c.ExpectedWarnings, actualWarnings); d != "" {
+				t.Errorf("Warnings don't match; diff\n%v", d)
+			}
+		})
+	}
+}
+
+func TestLuceneToQuery(t *testing.T) {
+	type testCase struct {
+		Name             string
+		Input            string
+		Expected         string
+		ExpectedWarnings []string
+	}
+
+	cases := []testCase{
+		{
+			Name:     "default-or",
+			Input:    `-log.level:debug timeout "connection reset"`,
+			Expected: `-status:debug (timeout OR "connection reset")`,
+		},
+		{
+			Name:     "required-drops-optional",
+			Input:    `+service.name:api -log.level:debug timeout "connection reset"`,
+			Expected: "service:api -status:debug",
+			ExpectedWarnings: []string{
+				"column 36: timeout: optional terms only affect scoring when there are +required terms; dropped",
+				`column 44: "connection reset": optional terms only affect scoring when there are +required terms; dropped`,
+			},
+		},
+		{
+			Name:     "required-text",
+			Input:    "+a b",
+			Expected: "a",
+			ExpectedWarnings: []string{
+				"column 4: b: optional terms only affect scoring when there are +required terms; dropped",
+			},
+		},
+		{
+			Name:     "explicit-and",
+			Input:    `_exists_:user.id AND response_time:[100 TO *]`,
+			Expected: "@response_time:[100 TO *] @user.id:*",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			actual, err := LuceneToQuery(c.Input, nil)
+			if err != nil {
+				t.Fatalf("Failed to convert %v: %+v", c.Input, err)
+			}
+			if actual.Query != c.Expected {
+				t.Errorf("Query doesn't match; got %v; want %v", actual.Query, c.Expected)
+			}
+
+			var actualWarnings []string
+			for _, w := range actual.Warnings {
+				actualWarnings = append(actualWarnings, w.String())
+			}
+			if d := cmp.Diff(c.ExpectedWarnings, actualWarnings); d != "" {
+				t.Errorf("Warnings don't match; diff\n%v", d)
+			}
+		})
+	}
+}
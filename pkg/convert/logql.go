@@ -0,0 +1,564 @@
+package convert
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/jlewi/ddctl/pkg/ddog"
+	"github.com/jlewi/ddctl/pkg/query"
+	"github.com/pkg/errors"
+)
+
+var (
+	// DefaultLabelMapping maps common Loki labels to the equivalent Datadog tags and reserved attributes.
+	DefaultLabelMapping = map[string]string{
+		"app":            "service",
+		"service_name":   "service",
+		"level":          "status",
+		"detected_level": "status",
+		"namespace":      "kube_namespace",
+		"pod":            "pod_name",
+		"container":      "container_name",
+		"cluster":        "kube_cluster_name",
+	}
+
+	// vectorAggregations are the LogQL aggregations over labels e.g. sum by (app) (...)
+	vectorAggregations = map[string]bool{
+		"sum": true, "count": true, "avg": true, "min": true, "max": true, "stddev": true, "stdvar": true,
+		"topk": true, "bottomk": true, "sort": true, "sort_desc": true,
+	}
+
+	// rangeAggregations are the LogQL aggregations over a time range e.g. count_over_time({app="foo"}[5m])
+	rangeAggregations = map[string]bool{
+		"count_over_time": true, "rate": true, "bytes_over_time": true, "bytes_rate": true, "absent_over_time": true,
+		"sum_over_time": true, "avg_over_time": true, "min_over_time": true, "max_over_time": true,
+		"stddev_over_time": true, "stdvar_over_time": true, "quantile_over_time": true, "first_over_time": true,
+		"last_over_time": true, "rate_counter": true,
+	}
+)
+
+// LogQLResult is the Datadog equivalent of a LogQL query.
+type LogQLResult struct {
+	// Query is the Datadog search query
+	Query string
+	// AggType is the aggregation of a metric query e.g. count. It is empty for log queries.
+	AggType string
+	// GroupBy are the Datadog fields a metric query is grouped by
+	GroupBy []string
+	// Warnings are the constructs that couldn't be translated exactly
+	Warnings []Warning
+}
+
+// LogQLToQuery translates a LogQL query into a Datadog search query.
+//
+// Stream selectors become tags mapped with labelMapping and line filters become free text. Labels extracted
+// by a parser (e.g. | json) become facets unless they are in labelMapping. Metric queries counting logs
+// (count_over_time and rate) are translated into a count grouped by the labels of the outer aggregation.
+func LogQLToQuery(expr string, labelMapping map[string]string) (*LogQLResult, error) {
+	p := &logqlParser{
+		src:     expr,
+		labels:  MergeMappings(DefaultLabelMapping, labelMapping),
+		b:       ddog.Q(),
+		result:  &LogQLResult{},
+		matched: map[string]bool{},
+	}
+
+	p.skipSpace()
+	var err error
+	if p.peek() == '{' {
+		err = p.parseLogExpr()
+	} else {
+		err = p.parseMetricExpr()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpace()
+	if !p.eof() {
+		return nil, p.errorf("unexpected %q", p.src[p.pos:])
+	}
+
+	p.result.Query = p.b.String()
+	return p.result, nil
+}
+
+type logqlParser struct {
+	src    string
+	pos    int
+	labels map[string]string
+	b      *ddog.QueryBuilder
+	result *LogQLResult
+	// matched are the labels used in the stream selector
+	matched map[string]bool
+}
+
+func (p *logqlParser) errorf(format string, args ...any) error {
+	return errors.Errorf("invalid LogQL at column %d: %v", p.pos+1, errors.Errorf(format, args...))
+}
+
+func (p *logqlParser) warn(start int, format string, args ...any) {
+	construct := strings.TrimSpace(p.src[start:p.pos])
+	p.result.Warnings = append(p.result.Warnings, warnAt(p.src, start, construct, format, args...))
+}
+
+func (p *logqlParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *logqlParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *logqlParser) skipSpace() {
+	for !p.eof() && strings.ContainsRune(" \t\n\r", rune(p.peek())) {
+		p.pos++
+	}
+}
+
+// consume skips whitespace and then s if it is next in the query.
+func (p *logqlParser) consume(s string) bool {
+	p.skipSpace()
+	if strings.HasPrefix(p.src[p.pos:], s) {
+		p.pos += len(s)
+		return true
+	}
+	return false
+}
+
+func (p *logqlParser) expect(s string) error {
+	if !p.consume(s) {
+		return p.errorf("expected %q", s)
+	}
+	return nil
+}
+
+func (p *logqlParser) readIdent() string {
+	p.skipSpace()
+	start := p.pos
+	for !p.eof() {
+		c := p.peek()
+		if !(c == '_' || c == '.' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')) {
+			break
+		}
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+// readString reads a double quoted or backtick quoted string.
+func (p *logqlParser) readString() (string, error) {
+	p.skipSpace()
+	start := p.pos
+	switch p.peek() {
+	case '`':
+		end := strings.IndexByte(p.src[p.pos+1:], '`')
+		if end < 0 {
+			return "", p.errorf("unterminated string")
+		}
+		p.pos += end + 2
+		return p.src[start+1 : p.pos-1], nil
+	case '"':
+		for p.pos++; !p.eof() && p.peek() != '"'; p.pos++ {
+			if p.peek() == '\\' {
+				p.pos++
+			}
+		}
+		if p.eof() {
+			return "", p.errorf("unterminated string")
+		}
+		p.pos++
+		value, err := strconv.Unquote(p.src[start:p.pos])
+		if err != nil {
+			return "", p.errorf("invalid string %v", p.src[start:p.pos])
+		}
+		return value, nil
+	}
+	return "", p.errorf("expected a string")
+}
+
+// readOp reads one of the comparison operators.
+func (p *logqlParser) readOp() string {
+	p.skipSpace()
+	for _, op := range []string{"=~", "!~", "!=", "==", ">=", "<=", "=", ">", "<"} {
+		if strings.HasPrefix(p.src[p.pos:], op) {
+			p.pos += len(op)
+			return op
+		}
+	}
+	return ""
+}
+
+// skipUntil skips to the next character in stops that isn't in a string or parentheses, or to an unmatched ).
+// If stops contains ! it only stops at the line filters != and !~.
+func (p *logqlParser) skipUntil(stops string) {
+	depth := 0
+	for !p.eof() {
+		c := p.peek()
+		switch {
+		case c == '"' || c == '`':
+			if _, err := p.readString(); err != nil {
+				p.pos = len(p.src)
+			}
+			continue
+		case c == '(':
+			depth++
+		case c == ')':
+			if depth == 0 {
+				return
+			}
+			depth--
+		case depth == 0 && c == '!' && strings.ContainsRune(stops, '!'):
+			if strings.HasPrefix(p.src[p.pos:], "!=") || strings.HasPrefix(p.src[p.pos:], "!~") {
+				return
+			}
+		case depth == 0 && strings.ContainsRune(stops, rune(c)):
+			return
+		}
+		p.pos++
+	}
+}
+
+// skipStage skips to the start of the next pipeline stage or the end of the log expression.
+func (p *logqlParser) skipStage() {
+	p.skipUntil("|[!")
+}
+
+func (p *logqlParser) mapLabel(label string) string {
+	if tag, ok := p.labels[label]; ok {
+		return tag
+	}
+	return label
+}
+
+func (p *logqlParser) parseMetricExpr() error {
+	p.skipSpace()
+	start := p.pos
+	name := p.readIdent()
+	switch {
+	case vectorAggregations[name]:
+		by, err := p.parseGrouping(start)
+		if err != nil {
+			return err
+		}
+		if err := p.expect("("); err != nil {
+			return err
+		}
+		if name == "topk" || name == "bottomk" {
+			p.skipUntil(",")
+			if err := p.expect(","); err != nil {
+				return err
+			}
+		}
+		if err := p.parseMetricExpr(); err != nil {
+			return err
+		}
+		if err := p.expect(")"); err != nil {
+			return err
+		}
+		if by == nil {
+			if by, err = p.parseGrouping(start); err != nil {
+				return err
+			}
+		}
+		if name != "sum" && name != "count" {
+			p.warn(start, "%v isn't supported; the query is translated into a count of the matching logs", name)
+		}
+		if by != nil {
+			p.result.GroupBy = by
+		}
+		return nil
+	case rangeAggregations[name]:
+		if err := p.expect("("); err != nil {
+			return err
+		}
+		if err := p.parseLogExpr(); err != nil {
+			return err
+		}
+		if err := p.expect("["); err != nil {
+			return err
+		}
+		p.skipUntil("]")
+		if err := p.expect("]"); err != nil {
+			return err
+		}
+		if err := p.expect(")"); err != nil {
+			return err
+		}
+		p.result.AggType = "count"
+		switch name {
+		case "count_over_time":
+		case "rate":
+			p.warn(start, "rate is per second; Datadog shows the count of logs in each interval")
+		default:
+			p.warn(start, "%v isn't supported; the query is translated into a count of the matching logs", name)
+		}
+		return nil
+	case name == "":
+		return p.errorf("expected a stream selector or a metric aggregation")
+	}
+	return p.errorf("unsupported function %v", name)
+}
+
+// parseGrouping parses an optional by or without clause and returns the mapped labels.
+func (p *logqlParser) parseGrouping(start int) ([]string, error) {
+	save := p.pos
+	kw := p.readIdent()
+	if kw != "by" && kw != "without" {
+		p.pos = save
+		return nil, nil
+	}
+
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var labels []string
+	for !p.consume(")") {
+		label := p.readIdent()
+		if label == "" {
+			return nil, p.errorf("expected a label")
+		}
+		labels = append(labels, p.mapLabel(label))
+		p.consume(",")
+	}
+
+	if kw == "without" {
+		p.warn(start, "grouping without labels isn't supported")
+		return nil, nil
+	}
+	return labels, nil
+}
+
+func (p *logqlParser) parseLogExpr() error {
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	for !p.consume("}") {
+		p.skipSpace()
+		start := p.pos
+		label := p.readIdent()
+		op := p.readOp()
+		if label == "" || op == "" {
+			return p.errorf("expected a label matcher")
+		}
+		value, err := p.readString()
+		if err != nil {
+			return err
+		}
+		p.matched[label] = true
+		p.addMatcher(start, p.mapLabel(label), op, value)
+		p.consume(",")
+	}
+
+	for {
+		p.skipSpace()
+		start := p.pos
+		switch {
+		case p.eof() || p.peek() == ')' || p.peek() == '[':
+			return nil
+		case p.consume("|=") || p.consume("!=") || p.consume("|~") || p.consume("!~"):
+			op := p.src[p.pos-2 : p.pos]
+			if err := p.parseLineFilter(start, op); err != nil {
+				return err
+			}
+		case p.consume("|"):
+			if err := p.parseStage(start); err != nil {
+				return err
+			}
+		default:
+			return p.errorf("unexpected %q", p.src[p.pos:])
+		}
+	}
+}
+
+func (p *logqlParser) parseLineFilter(start int, op string) error {
+	var values []string
+	for {
+		value, err := p.readString()
+		if err != nil {
+			return err
+		}
+		values = append(values, value)
+		save := p.pos
+		if p.readIdent() != "or" {
+			p.pos = save
+			break
+		}
+	}
+
+	var terms []*ddog.QueryBuilder
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+		if op[1] == '=' {
+			terms = append(terms, ddog.Q().Text(value))
+			continue
+		}
+		// Line filters match anywhere in the line
+		texts, ok := translateRegex(value, regexPartialMatch)
+		if !ok {
+			p.warn(start, "the regular expression can't be translated; Datadog search only supports wildcards")
+			return nil
+		}
+		if texts.FoldCase {
+			p.warn(start, foldCaseWarning)
+		}
+		for _, t := range texts.Values {
+			terms = append(terms, ddog.Q().Term(t))
+		}
+	}
+
+	if op[0] == '!' {
+		// The line must not contain any of the values.
+		for _, t := range terms {
+			p.b.Not(t)
+		}
+		return nil
+	}
+	p.b.Or(terms...)
+	return nil
+}
+
+// parseStage parses a pipeline stage following the | at start.
+func (p *logqlParser) parseStage(start int) error {
+	name := p.readIdent()
+	switch name {
+	case "json", "logfmt":
+		// Datadog parses JSON logs automatically and logfmt with a pipeline so the parser isn't needed.
+		argsStart := p.pos
+		p.skipStage()
+		if strings.TrimSpace(p.src[argsStart:p.pos]) != "" {
+			p.warn(start, "parser expressions aren't supported; attributes are extracted by the Datadog pipeline")
+		}
+		return nil
+	}
+
+	save := p.pos
+	if name != "" && p.readOp() != "" {
+		p.pos = save
+		return p.parseLabelFilters(name)
+	}
+
+	p.skipStage()
+	p.warn(start, "the pipeline stage isn't supported")
+	return nil
+}
+
+// parseLabelFilters parses label filters on extracted labels e.g. status_code >= 500 and method="GET".
+func (p *logqlParser) parseLabelFilters(label string) error {
+	for {
+		filterStart := p.pos
+		op := p.readOp()
+		p.skipSpace()
+		var value string
+		if c := p.peek(); c == '"' || c == '`' {
+			v, err := p.readString()
+			if err != nil {
+				return err
+			}
+			value = v
+		} else {
+			valueStart := p.pos
+			for !p.eof() && !strings.ContainsRune(" \t\n|),", rune(p.peek())) {
+				p.pos++
+			}
+			value = p.src[valueStart:p.pos]
+		}
+
+		key := p.mapLabel(label)
+		if _, ok := p.labels[label]; !ok && !p.matched[label] {
+			key = "@" + label
+		}
+		p.addLabelFilter(filterStart-len(label), key, op, value)
+
+		save := p.pos
+		next := p.readIdent()
+		switch {
+		case next == "and":
+		case p.consume(","):
+		case next == "or":
+			p.pos = save
+			p.skipSpace()
+			orStart := p.pos
+			p.skipStage()
+			p.warn(orStart, "or between label filters isn't supported; only the filters before or are translated")
+			return nil
+		default:
+			p.pos = save
+			return nil
+		}
+		if label = p.readIdent(); label == "" {
+			return p.errorf("expected a label filter")
+		}
+	}
+}
+
+func (p *logqlParser) addMatcher(start int, key string, op string, value string) {
+	exists := &query.Field{Key: key, Value: &query.Text{Value: "*"}}
+	switch op {
+	case "=":
+		if value == "" {
+			p.b.Term(&query.Not{Operand: exists})
+			return
+		}
+		p.b.Tag(key, value)
+	case "!=":
+		if value == "" {
+			p.b.Term(exists)
+			return
+		}
+		p.b.Not(ddog.Q().Tag(key, value))
+	case "=~", "!~":
+		field, foldCase, ok := regexField(key, value)
+		if !ok {
+			p.warn(start, "the regular expression can't be translated; Datadog search only supports wildcards")
+			return
+		}
+		if foldCase {
+			p.warn(start, foldCaseWarning)
+		}
+		if op == "!~" {
+			p.b.Term(&query.Not{Operand: field})
+			return
+		}
+		p.b.Term(field)
+	}
+}
+
+func (p *logqlParser) addLabelFilter(start int, key string, op string, value string) {
+	switch op {
+	case "=", "==", "!=", "=~", "!~":
+		if op == "==" {
+			op = "="
+		}
+		p.addMatcher(start, key, op, value)
+	default:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			p.warn(start, "only numeric comparisons are supported")
+			return
+		}
+		p.b.Term(&query.Field{Key: key, Value: &query.Comparison{Op: op, Value: value}})
+	}
+}
+
+// regexField returns the field matching the values of a regular expression. LogQL label matchers match the
+// whole value. It also returns whether the regex is case insensitive.
+func regexField(key string, re string) (*query.Field, bool, bool) {
+	values, ok := translateRegex(re, regexFullMatch)
+	if !ok {
+		return nil, false, false
+	}
+	if len(values.Values) == 1 {
+		return &query.Field{Key: key, Value: values.Values[0]}, values.FoldCase, true
+	}
+
+	operands := make([]query.Node, 0, len(values.Values))
+	for _, v := range values.Values {
+		operands = append(operands, v)
+	}
+	return &query.Field{Key: key, Value: &query.Group{Expr: &query.Or{Operands: operands}}}, values.FoldCase, true
+}
@@ -0,0 +1,116 @@
+package convert
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLogQLToQuery(t *testing.T) {
+	type testCase struct {
+		Name             string
+		Input            string
+		LabelMapping     map[string]string
+		ExpectedQuery    string
+		ExpectedAggType  string
+		ExpectedGroupBy  []string
+		ExpectedWarnings []string
+	}
+
+	cases := []testCase{
+		{
+			Name:          "stream-selector",
+			Input:         `{app="feserver", env=~"prod|staging", level!="debug", region=~"us-.*"}`,
+			ExpectedQuery: `service:feserver env:(prod OR staging) -status:debug region:us-*`,
+		},
+		{
+			Name:          "line-filters",
+			Input:         `{app="feserver"} |= "connection reset" != "healthz" |~ "timeout|deadline"`,
+			ExpectedQuery: `service:feserver "connection reset" -healthz (*timeout* OR *deadline*)`,
+		},
+		{
+			Name:          "anchored-line-filters",
+			Input:         `{app="feserver"} |~ "^GET /api" !~ "healthz$"`,
+			ExpectedQuery: `service:feserver GET\ \/api* -*healthz`,
+		},
+		{
+			Name:          "case-insensitive",
+			Input:         `{app=~"(?i)feserver"} |~ "(?i)timeout"`,
+			ExpectedQuery: `service:feserver *timeout*`,
+			ExpectedWarnings: []string{
+				`column 2: app=~"(?i)feserver": the regular expression is case insensitive but Datadog values only match the case they are written in`,
+				`column 23: |~ "(?i)timeout": the regular expression is case insensitive but Datadog values only match the case they are written in`,
+			},
+		},
+		{
+			Name:          "label-filters",
+			Input:         `{app="feserver"} | json | status_code >= 500 and method="GET" | level="error"`,
+			ExpectedQuery: `service:feserver @status_code:>=500 @method:GET status:error`,
+		},
+		{
+			Name:          "label-mapping",
+			Input:         `{job="default/feserver"}`,
+			LabelMapping:  map[string]string{"job": "service"},
+			ExpectedQuery: `service:"default/feserver"`,
+		},
+		{
+			Name:            "metric",
+			Input:           `sum by (app, level) (count_over_time({namespace="prod"} |= "error" [5m]))`,
+			ExpectedQuery:   `kube_namespace:prod error`,
+			ExpectedAggType: "count",
+			ExpectedGroupBy: []string{"service", "status"},
+		},
+		{
+			Name:          "unsupported",
+			Input:         `{app="feserver"} |~ "err[0-9]+" | line_format "{{.msg}}" | duration > 10s`,
+			ExpectedQuery: `service:feserver`,
+			ExpectedWarnings: []string{
+				`column 18: |~ "err[0-9]+": the regular expression can't be translated; Datadog search only supports wildcards`,
+				`column 33: | line_format "{{.msg}}": the pipeline stage isn't supported`,
+				`column 60: duration > 10s: only numeric comparisons are supported`,
+			},
+		},
+		{
+			Name:             "rate",
+			Input:            `topk(5, rate({app="feserver"}[1m]))`,
+			ExpectedQuery:    `service:feserver`,
+			ExpectedAggType:  "count",
+			ExpectedWarnings: []string{"column 9: rate({app=\"feserver\"}[1m]): rate is per second; Datadog shows the count of logs in each interval", "column 1: topk(5, rate({app=\"feserver\"}[1m])): topk isn't supported; the query is translated into a count of the matching logs"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			actual, err := LogQLToQuery(c.Input, c.LabelMapping)
+			if err != nil {
+				t.Fatalf("Failed to convert %v: %+v", c.Input, err)
+			}
+
+			if actual.Query != c.ExpectedQuery {
+				t.Errorf("Query doesn't match; got %v;\n Want %v", actual.Query, c.ExpectedQuery)
+			}
+			if actual.AggType != c.ExpectedAggType {
+				t.Errorf("AggType doesn't match; got %v; want %v", actual.AggType, c.ExpectedAggType)
+			}
+			if d := cmp.Diff(c.ExpectedGroupBy, actual.GroupBy); d != "" {
+				t.Errorf("GroupBy doesn't match; diff\n%v", d)
+			}
+
+			var warnings []string
+			for _, w := range actual.Warnings {
+				warnings = append(warnings, w.String())
+			}
+			if d := cmp.Diff(c.ExpectedWarnings, warnings); d != "" {
+				t.Errorf("Warnings don't match; diff\n%v", d)
+			}
+		})
+	}
+}
+
+func TestLogQLToQueryErrors(t *testing.T) {
+	for _, input := range []string{`{app="feserver"`, `foo({app="x"}[5m])`, `{app=feserver}`} {
+		if _, err := LogQLToQuery(input, nil); err == nil {
+			t.Errorf("Expected an error converting %v", input)
+		}
+	}
+}
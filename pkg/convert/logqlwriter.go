@@ -0,0 +1,466 @@
+package convert
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jlewi/ddctl/api"
+	"github.com/jlewi/ddctl/pkg/query"
+	"github.com/pkg/errors"
+)
+
+const (
+	// logqlAutoInterval is the Grafana variable for the range of a metric query chosen from the time window
+	logqlAutoInterval = "[$__auto]"
+)
+
+var (
+	// DefaultTagMapping maps Datadog tags to the Loki labels used when exporting links to Grafana.
+	// Tags that aren't mapped are used as labels with the same name.
+	DefaultTagMapping = map[string]string{
+		"service":           "service_name",
+		"status":            "level",
+		"kube_namespace":    "namespace",
+		"pod_name":          "pod",
+		"container_name":    "container",
+		"kube_cluster_name": "cluster",
+	}
+
+	// invalidLabelChars are the characters that aren't allowed in a Loki label name
+	invalidLabelChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+	// logqlRangeAggregations maps Datadog aggregation types to the LogQL range aggregation over an unwrapped label
+	logqlRangeAggregations = map[string]string{
+		"sum": "sum_over_time",
+		"avg": "avg_over_time",
+		"min": "min_over_time",
+		"max": "max_over_time",
+	}
+)
+
+// InvertMapping returns the mapping from the values to the keys. If several keys have the same value the first
+// key in sorted order is used.
+func InvertMapping(m map[string]string) map[string]string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	inverted := make(map[string]string, len(m))
+	for _, k := range keys {
+		if _, ok := inverted[m[k]]; !ok {
+			inverted[m[k]] = k
+		}
+	}
+	return inverted
+}
+
+// LogQLExport is the LogQL equivalent of a DatadogLink.
+type LogQLExport struct {
+	// Expr is the LogQL expression
+	Expr string
+	// Warnings are the constructs that couldn't be translated exactly
+	Warnings []Warning
+}
+
+// LinkToLogQL translates the query of a DatadogLink into LogQL.
+//
+// Tags become stream matchers using tagMapping, free text becomes line filters and facets become label filters
+// after a json parser e.g. @http.status_code:>=500 becomes | http_status_code >= 500. If the link aggregates
+// logs the expression is a metric query e.g. a count grouped by host becomes
+// sum by (host) (count_over_time({...} [$__auto])). Terms that LogQL can't express, such as ORs across
+// different kinds of terms, are dropped and reported as warnings.
+func LinkToLogQL(link *api.DatadogLink, tagMapping map[string]string) (*LogQLExport, error) {
+	w := &logqlWriter{
+		tags:   MergeMappings(DefaultTagMapping, tagMapping),
+		result: &LogQLExport{},
+	}
+
+	if link.Query != "" {
+		n, err := query.Parse(link.Query)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to parse query %v", link.Query)
+		}
+		conjuncts := []query.Node{n}
+		if and, ok := n.(*query.And); ok {
+			conjuncts = and.Operands
+		}
+		for _, c := range conjuncts {
+			w.write(c)
+		}
+	}
+
+	if !w.positive {
+		label := w.label("service")
+		w.matchers = append(w.matchers, label+`=~".+"`)
+		w.warn(link.Query, "LogQL requires a stream matcher; matching any %v", label)
+	}
+
+	if len(link.Indexes) > 0 {
+		w.warn("indexes "+strings.Join(link.Indexes, ","), "indexes aren't exported")
+	}
+	if len(link.Queries) > 0 {
+		w.warn("queries", "multi-query views aren't exported")
+	}
+
+	w.result.Expr = w.metric(link)
+	return w.result, nil
+}
+
+type logqlWriter struct {
+	tags         map[string]string
+	matchers     []string
+	lineFilters  []string
+	labelFilters []string
+	// parse is true if the labels of the log lines need to be extracted with | json
+	parse bool
+	// positive is true if there is a stream matcher that doesn't match empty values
+	positive bool
+	result   *LogQLExport
+}
+
+func (w *logqlWriter) warn(construct string, format string, args ...any) {
+	w.result.Warnings = append(w.result.Warnings, warn(construct, format, args...))
+}
+
+// label returns the Loki label for a Datadog tag.
+func (w *logqlWriter) label(tag string) string {
+	if label, ok := w.tags[tag]; ok {
+		return label
+	}
+	return invalidLabelChars.ReplaceAllString(tag, "_")
+}
+
+// extracted returns the label the json parser extracts for a facet e.g. @http.method becomes http_method.
+func (w *logqlWriter) extracted(facet string) string {
+	w.parse = true
+	return invalidLabelChars.ReplaceAllString(strings.TrimPrefix(facet, "@"), "_")
+}
+
+// log returns the log query; the stream selector followed by the line filters, the parser and the label filters.
+func (w *logqlWriter) log() string {
+	parts := []string{"{" + strings.Join(w.matchers, ", ") + "}"}
+	parts = append(parts, w.lineFilters...)
+	if w.parse {
+		parts = append(parts, "| json")
+	}
+	for _, f := range w.labelFilters {
+		parts = append(parts, "| "+f)
+	}
+	return strings.Join(parts, " ")
+}
+
+// write translates a conjunct of the query.
+func (w *logqlWriter) write(n query.Node) {
+	negated := false
+	if not, ok := n.(*query.Not); ok {
+		negated = true
+		n = not.Operand
+	}
+
+	switch v := n.(type) {
+	case *query.Group:
+		if negated {
+			w.warn(n.String(), "negated groups aren't supported")
+			return
+		}
+		w.write(v.Expr)
+	case *query.And:
+		if negated {
+			w.warn(n.String(), "negated groups aren't supported")
+			return
+		}
+		for _, o := range v.Operands {
+			w.write(o)
+		}
+	case *query.Text:
+		re, ok := valueRegex(v)
+		if !ok {
+			op := "|="
+			if negated {
+				op = "!="
+			}
+			w.lineFilters = append(w.lineFilters, op+" "+strconv.Quote(unescape(v)))
+			return
+		}
+		op := "|~"
+		if negated {
+			op = "!~"
+		}
+		w.lineFilters = append(w.lineFilters, op+" "+logqlRegex(re))
+	case *query.Field:
+		if v.IsFacet() {
+			expr, ok := w.labelFilter(w.extracted(v.Key), v.Value, negated)
+			if !ok {
+				w.warn(v.String(), "the value isn't supported")
+				return
+			}
+			w.labelFilters = append(w.labelFilters, expr)
+			return
+		}
+		w.writeTag(v, negated)
+	case *query.Or:
+		w.writeOr(v, negated)
+	default:
+		w.warn(n.String(), "the term isn't supported")
+	}
+}
+
+// writeTag translates a tag into a stream matcher or, for comparisons, a label filter.
+func (w *logqlWriter) writeTag(f *query.Field, negated bool) {
+	label := w.label(f.Key)
+	switch v := f.Value.(type) {
+	case *query.Text:
+		if v.Value == "*" && !v.Quoted {
+			if negated {
+				w.matchers = append(w.matchers, label+`=""`)
+				return
+			}
+			w.matchers = append(w.matchers, label+`=~".+"`)
+			w.positive = true
+			return
+		}
+	case *query.Comparison, *query.Range:
+		expr, ok := w.labelFilter(label, f.Value, negated)
+		if !ok {
+			w.warn(f.String(), "the value isn't supported")
+			return
+		}
+		w.labelFilters = append(w.labelFilters, expr)
+		return
+	}
+
+	if t, ok := f.Value.(*query.Text); ok {
+		if _, isRegex := valueRegex(t); !isRegex {
+			op := "="
+			if negated {
+				op = "!="
+			}
+			w.matchers = append(w.matchers, label+op+strconv.Quote(unescape(t)))
+			w.positive = w.positive || !negated
+			return
+		}
+	}
+
+	re, ok := valueRegex(f.Value)
+	if !ok {
+		w.warn(f.String(), "the value isn't supported")
+		return
+	}
+	op := "=~"
+	if negated {
+		op = "!~"
+	}
+	w.matchers = append(w.matchers, label+op+logqlRegex(re))
+	w.positive = w.positive || !negated
+}
+
+// writeOr translates an OR of free text into a regular expression line filter or an OR of fields into a
+// label filter.
+func (w *logqlWriter) writeOr(or *query.Or, negated bool) {
+	allText := true
+	for _, o := range or.Operands {
+		if _, ok := o.(*query.Text); !ok {
+			allText = false
+		}
+	}
+	if allText {
+		alternatives := make([]string, 0, len(or.Operands))
+		for _, o := range or.Operands {
+			re, ok := valueRegex(o)
+			if !ok {
+				re = regexp.QuoteMeta(unescape(o.(*query.Text)))
+			}
+			alternatives = append(alternatives, re)
+		}
+		op := "|~"
+		if negated {
+			op = "!~"
+		}
+		w.lineFilters = append(w.lineFilters, op+" "+logqlRegex(strings.Join(alternatives, "|")))
+		return
+	}
+
+	if negated {
+		w.warn(or.String(), "negated ORs of fields aren't supported")
+		return
+	}
+
+	exprs := make([]string, 0, len(or.Operands))
+	for _, o := range or.Operands {
+		f, ok := o.(*query.Field)
+		if !ok {
+			w.warn(or.String(), "ORs can only combine free text or fields")
+			return
+		}
+		name := w.label(f.Key)
+		if f.IsFacet() {
+			name = w.extracted(f.Key)
+		}
+		expr, ok := w.labelFilter(name, f.Value, false)
+		if !ok {
+			w.warn(or.String(), "the value of %v isn't supported", f.Key)
+			return
+		}
+		exprs = append(exprs, expr)
+	}
+	w.labelFilters = append(w.labelFilters, "("+strings.Join(exprs, " or ")+")")
+}
+
+// labelFilter returns the label filter expression matching the value of a label.
+func (w *logqlWriter) labelFilter(name string, value query.Node, negated bool) (string, bool) {
+	switch v := value.(type) {
+	case *query.Comparison:
+		op := v.Op
+		if negated {
+			op = map[string]string{">": "<=", ">=": "<", "<": ">=", "<=": ">"}[op]
+		}
+		return fmt.Sprintf("%v %v %v", name, op, v.Value), true
+	case *query.Range:
+		if negated {
+			return "", false
+		}
+		var parts []string
+		if v.Low != "*" {
+			op := ">="
+			if v.ExclusiveLow {
+				op = ">"
+			}
+			parts = append(parts, fmt.Sprintf("%v %v %v", name, op, v.Low))
+		}
+		if v.High != "*" {
+			op := "<="
+			if v.ExclusiveHigh {
+				op = "<"
+			}
+			parts = append(parts, fmt.Sprintf("%v %v %v", name, op, v.High))
+		}
+		if len(parts) == 0 {
+			return "", false
+		}
+		return strings.Join(parts, " and "), true
+	case *query.Text:
+		if v.Value == "*" && !v.Quoted {
+			if negated {
+				return name + `=""`, true
+			}
+			return name + `!=""`, true
+		}
+		if _, ok := valueRegex(v); !ok {
+			op := "="
+			if negated {
+				op = "!="
+			}
+			return name + op + strconv.Quote(unescape(v)), true
+		}
+	}
+
+	re, ok := valueRegex(value)
+	if !ok {
+		return "", false
+	}
+	op := "=~"
+	if negated {
+		op = "!~"
+	}
+	return name + op + logqlRegex(re), true
+}
+
+// valueRegex returns the regular expression for a wildcard or an OR of values e.g. (a OR b*). It returns false
+// if the value is a single literal or can't be expressed as a regular expression.
+func valueRegex(n query.Node) (string, bool) {
+	switch v := n.(type) {
+	case *query.Text:
+		if !v.HasWildcard() {
+			return "", false
+		}
+		return wildcardToRegex(v.Value), true
+	case *query.Group:
+		or, ok := v.Expr.(*query.Or)
+		if !ok {
+			return valueRegex(v.Expr)
+		}
+		alternatives := make([]string, 0, len(or.Operands))
+		for _, o := range or.Operands {
+			t, ok := o.(*query.Text)
+			if !ok {
+				return "", false
+			}
+			re, ok := valueRegex(t)
+			if !ok {
+				re = regexp.QuoteMeta(unescape(t))
+			}
+			alternatives = append(alternatives, re)
+		}
+		return strings.Join(alternatives, "|"), true
+	}
+	return "", false
+}
+
+// logqlRegex quotes a regular expression. Backticks are used to avoid escaping backslashes.
+func logqlRegex(re string) string {
+	if strings.Contains(re, "`") {
+		return strconv.Quote(re)
+	}
+	return "`" + re + "`"
+}
+
+// metric wraps the log query in a metric query if the link aggregates logs.
+func (w *logqlWriter) metric(link *api.DatadogLink) string {
+	if link.AggType == "" {
+		return w.log()
+	}
+
+	var groupBy []string
+	for _, g := range strings.Split(link.GroupBy, ",") {
+		g = strings.TrimSpace(g)
+		switch {
+		case g == "":
+		case strings.HasPrefix(g, "@"):
+			groupBy = append(groupBy, w.extracted(g))
+		default:
+			groupBy = append(groupBy, w.label(g))
+		}
+	}
+	// Compute the log query after the group by which may require the parser
+	logQuery := w.log()
+	by := ""
+	if len(groupBy) > 0 {
+		by = " by (" + strings.Join(groupBy, ", ") + ")"
+	}
+
+	if link.AggType == "count" {
+		return fmt.Sprintf("sum%v (count_over_time(%v %v))", by, logQuery, logqlAutoInterval)
+	}
+
+	measure := link.GroupInto
+	if !strings.HasPrefix(measure, "@") {
+		w.warn("aggType "+link.AggType, "only counts and aggregations of facets are exported")
+		return logQuery
+	}
+
+	w.parse = true
+	unwrapped := fmt.Sprintf("%v | unwrap %v %v", w.log(), w.extracted(measure), logqlAutoInterval)
+	if fn, ok := logqlRangeAggregations[link.AggType]; ok {
+		return fmt.Sprintf("%v(%v)%v", fn, unwrapped, by)
+	}
+
+	quantile := ""
+	switch {
+	case link.AggType == "median":
+		quantile = "0.5"
+	case strings.HasPrefix(link.AggType, "pc"):
+		if pc, err := strconv.Atoi(strings.TrimPrefix(link.AggType, "pc")); err == nil {
+			quantile = strconv.FormatFloat(float64(pc)/100, 'f', -1, 64)
+		}
+	}
+	if quantile == "" {
+		w.warn("aggType "+link.AggType, "the aggregation isn't supported")
+		return logQuery
+	}
+	return fmt.Sprintf("quantile_over_time(%v, %v)%v", quantile, unwrapped, by)
+}
@@ -0,0 +1,111 @@
+package convert
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jlewi/ddctl/api"
+)
+
+func TestLinkToLogQL(t *testing.T) {
+	type testCase struct {
+		Name             string
+		Input            *api.DatadogLink
+		Mapping          map[string]string
+		Expected         string
+		ExpectedWarnings []string
+	}
+
+	cases := []testCase{
+		{
+			Name: "logs",
+			Input: &api.DatadogLink{
+				Query: `service:feserver env:prod -kube_namespace:kube-system status:(error OR warn) "connection reset" -healthcheck *timeout* @http.status_code:>=500 -@http.method:GET @user.id:*`,
+			},
+			Expected: "{service_name=\"feserver\", env=\"prod\", namespace!=\"kube-system\", level=~`error|warn`} " +
+				"|= \"connection reset\" != \"healthcheck\" |~ `.*timeout.*` | json | http_status_code >= 500 | http_method!=\"GET\" | user_id!=\"\"",
+		},
+		{
+			Name: "or-and-mapping",
+			Input: &api.DatadogLink{
+				Query: `service:api* (@http.method:POST OR env:staging) (a OR b) (c OR service:x)`,
+			},
+			Mapping:  map[string]string{"service": "app"},
+			Expected: "{app=~`api.*`} |~ `a|b` | json | (http_method=\"POST\" or env=\"staging\")",
+			ExpectedWarnings: []string{
+				"c OR service:x: ORs can only combine free text or fields",
+			},
+		},
+		{
+			Name: "count",
+			Input: &api.DatadogLink{
+				Query:     "status:error",
+				AggType:   "count",
+				GroupInto: "count",
+				GroupBy:   "host,@http.route",
+			},
+			Expected: "sum by (host, http_route) (count_over_time({level=\"error\"} | json [$__auto]))",
+		},
+		{
+			Name: "percentile",
+			Input: &api.DatadogLink{
+				Query:     "-status:info",
+				AggType:   "pc99",
+				GroupInto: "@duration",
+				GroupBy:   "service",
+				Indexes:   []string{"main"},
+			},
+			Expected: "quantile_over_time(0.99, {level!=\"info\", service_name=~\".+\"} | json | unwrap duration [$__auto]) by (service_name)",
+			ExpectedWarnings: []string{
+				"-status:info: LogQL requires a stream matcher; matching any service_name",
+				"indexes main: indexes aren't exported",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			actual, err := LinkToLogQL(c.Input, c.Mapping)
+			if err != nil {
+				t.Fatalf("Failed to convert %v: %+v", c.Input.Query, err)
+			}
+			if actual.Expr != c.Expected {
+				t.Errorf("LogQL doesn't match; got\n%v\nwant\n%v", actual.Expr, c.Expected)
+			}
+
+			var actualWarnings []string
+			for _, w := range actual.Warnings {
+				actualWarnings = append(actualWarnings, w.String())
+			}
+			if d := cmp.Diff(c.ExpectedWarnings, actualWarnings); d != "" {
+				t.Errorf("Warnings don't match; diff\n%v", d)
+			}
+		})
+	}
+}
+
+func TestLinkToGrafanaLink_RoundTrip(t *testing.T) {
+	link := &api.DatadogLink{
+		Query:  `service:feserver status:error "connection reset" @duration:>=500`,
+		FromTS: "now-1h",
+		ToTS:   "now",
+	}
+	glink, _, err := LinkToGrafanaLink(link, GrafanaExportOptions{BaseURL: "https://grafana.acme.com", Datasource: "loki"})
+	if err != nil {
+		t.Fatalf("Failed to export link: %+v", err)
+	}
+
+	actual, warnings, err := GrafanaLinkToLink(glink, GrafanaOptions{BaseURL: "https://acme.datadoghq.com"})
+	if err != nil {
+		t.Fatalf("Failed to convert the exported link: %+v", err)
+	}
+	if len(warnings) > 0 {
+		t.Errorf("Unexpected warnings: %v", warnings)
+	}
+	if actual.Query != link.Query {
+		t.Errorf("Query doesn't round trip; got %v; want %v", actual.Query, link.Query)
+	}
+	if actual.FromTS != link.FromTS || actual.ToTS != link.ToTS {
+		t.Errorf("Time range doesn't round trip; got %v to %v", actual.FromTS, actual.ToTS)
+	}
+}
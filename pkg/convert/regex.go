@@ -0,0 +1,183 @@
+package convert
+
+import (
+	"regexp/syntax"
+	"strings"
+
+	"github.com/jlewi/ddctl/pkg/query"
+)
+
+const (
+	// foldCaseWarning is the warning for case insensitive regular expressions.
+	foldCaseWarning = "the regular expression is case insensitive but Datadog values only match the case they are written in"
+)
+
+// regexMatch is how a query language applies a regular expression to a value.
+type regexMatch int
+
+const (
+	// regexFullMatch means the regex must match the whole value e.g. LogQL label matchers.
+	regexFullMatch regexMatch = iota
+	// regexPartialMatch means the regex matches anywhere in the value unless it is anchored with ^ or $ e.g. LogQL
+	// line filters and Cloud Logging =~.
+	regexPartialMatch
+)
+
+// regexValues is the translation of a regular expression into Datadog values.
+type regexValues struct {
+	// Values are the values matching the regex; a value matches if it matches any of them.
+	Values []*query.Text
+	// FoldCase is true if the regex is case insensitive e.g. (?i)timeout. Datadog values are matched with the
+	// case they are written in so the translation only matches the case in the regex.
+	FoldCase bool
+}
+
+// translateRegex translates a regular expression into the values matching it in a Datadog query. It only supports
+// alternations of literals in which .* and .+ are wildcards e.g. prod|staging.* since Datadog's search syntax
+// doesn't have regular expressions. Unanchored ends of a partial match become wildcards e.g. ^web becomes web*.
+// It returns false if the regex can't be translated.
+func translateRegex(re string, match regexMatch) (*regexValues, bool) {
+	result := &regexValues{}
+	for _, alt := range splitAlternatives(re) {
+		pattern, foldCase, ok := regexToPattern(alt, match)
+		if !ok {
+			return nil, false
+		}
+		result.FoldCase = result.FoldCase || foldCase
+
+		if strings.ContainsAny(pattern, "*?") {
+			result.Values = append(result.Values, query.Wildcard(pattern))
+		} else {
+			result.Values = append(result.Values, query.Literal(pattern))
+		}
+	}
+	return result, len(result.Values) > 0
+}
+
+// splitAlternatives splits a regex on the | operators that aren't inside parentheses or character classes.
+// The alternatives are split before parsing because the regex parser factors out common prefixes.
+func splitAlternatives(re string) []string {
+	var alts []string
+	depth := 0
+	inClass := false
+	start := 0
+	for i := 0; i < len(re); i++ {
+		switch c := re[i]; {
+		case c == '\\':
+			i++
+		case inClass:
+			inClass = c != ']'
+		case c == '[':
+			inClass = true
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == '|' && depth == 0:
+			alts = append(alts, re[start:i])
+			start = i + 1
+		}
+	}
+	return append(alts, re[start:])
+}
+
+// regexToPattern translates a regex without alternations into a wildcard pattern. It also returns whether the
+// regex is case insensitive.
+func regexToPattern(re string, match regexMatch) (string, bool, bool) {
+	parsed, err := syntax.Parse(re, syntax.Perl)
+	if err != nil {
+		return "", false, false
+	}
+
+	subs := []*syntax.Regexp{parsed}
+	if parsed.Op == syntax.OpConcat {
+		subs = parsed.Sub
+	}
+	anchoredStart := len(subs) > 0 && isBeginAnchor(subs[0])
+	if anchoredStart {
+		subs = subs[1:]
+	}
+	anchoredEnd := len(subs) > 0 && isEndAnchor(subs[len(subs)-1])
+	if anchoredEnd {
+		subs = subs[:len(subs)-1]
+	}
+
+	w := &patternWriter{}
+	for _, sub := range subs {
+		if !w.write(sub) {
+			return "", false, false
+		}
+	}
+	pattern := w.sb.String()
+	if pattern == "" {
+		return "", false, false
+	}
+
+	if match == regexPartialMatch {
+		if !anchoredStart && !strings.HasPrefix(pattern, "*") {
+			pattern = "*" + pattern
+		}
+		if !anchoredEnd && !strings.HasSuffix(pattern, "*") {
+			pattern = pattern + "*"
+		}
+	}
+	return pattern, w.foldCase, true
+}
+
+// patternWriter writes a regex as a wildcard pattern.
+type patternWriter struct {
+	sb       strings.Builder
+	foldCase bool
+}
+
+func (w *patternWriter) write(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpLiteral:
+		lit := string(re.Rune)
+		if strings.ContainsAny(lit, "*?") {
+			// A literal wildcard character can't be told apart from a wildcard in the pattern.
+			return false
+		}
+		if re.Flags&syntax.FoldCase != 0 && strings.ToLower(lit) != strings.ToUpper(lit) {
+			// The parser stores case insensitive literals in upper case; lower case is more common in logs.
+			w.foldCase = true
+			lit = strings.ToLower(lit)
+		}
+		w.sb.WriteString(lit)
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		w.sb.WriteString("?")
+	case syntax.OpStar, syntax.OpPlus:
+		if !isAnyChar(re.Sub[0]) {
+			return false
+		}
+		if re.Op == syntax.OpPlus {
+			w.sb.WriteString("?")
+		}
+		w.sb.WriteString("*")
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if !w.write(sub) {
+				return false
+			}
+		}
+	case syntax.OpCapture:
+		return w.write(re.Sub[0])
+	case syntax.OpEmptyMatch:
+	default:
+		// Anchors are only supported at the start and end of the regex
+		return false
+	}
+	return true
+}
+
+func isAnyChar(re *syntax.Regexp) bool {
+	return re.Op == syntax.OpAnyChar || re.Op == syntax.OpAnyCharNotNL
+}
+
+func isBeginAnchor(re *syntax.Regexp) bool {
+	return re.Op == syntax.OpBeginText || re.Op == syntax.OpBeginLine
+}
+
+func isEndAnchor(re *syntax.Regexp) bool {
+	return re.Op == syntax.OpEndText || re.Op == syntax.OpEndLine
+}
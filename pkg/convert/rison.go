@@ -0,0 +1,188 @@
+package convert
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// risonNotIDChars are the characters that can't appear in an unquoted rison string
+	risonNotIDChars = " '!:(),*@$"
+)
+
+// decodeRison decodes a rison value (https://github.com/Nanonid/rison) e.g. the _a and _g state of a Kibana URL.
+// Objects decode to map[string]any, arrays to []any, numbers to float64, !t and !f to bool and !n to nil.
+func decodeRison(s string) (any, error) {
+	d := &risonDecoder{src: s}
+	v, err := d.value()
+	if err != nil {
+		return nil, err
+	}
+	if d.pos != len(d.src) {
+		return nil, d.errorf("unexpected %q after the value", d.src[d.pos])
+	}
+	return v, nil
+}
+
+type risonDecoder struct {
+	src string
+	pos int
+}
+
+func (d *risonDecoder) errorf(format string, args ...any) error {
+	return errors.Errorf("invalid rison at offset %d: %v", d.pos, errors.Errorf(format, args...))
+}
+
+func (d *risonDecoder) value() (any, error) {
+	if d.pos >= len(d.src) {
+		return nil, d.errorf("unexpected end of input")
+	}
+
+	switch c := d.src[d.pos]; {
+	case c == '(':
+		return d.object()
+	case c == '\'':
+		return d.quoted()
+	case c == '!':
+		if d.pos+1 >= len(d.src) {
+			return nil, d.errorf("unexpected end of input after !")
+		}
+		d.pos += 2
+		switch d.src[d.pos-1] {
+		case 't':
+			return true, nil
+		case 'f':
+			return false, nil
+		case 'n':
+			return nil, nil
+		case '(':
+			return d.array()
+		}
+		d.pos -= 2
+		return nil, d.errorf("unknown literal !%c", d.src[d.pos+1])
+	case c == '-' || (c >= '0' && c <= '9'):
+		return d.number()
+	}
+
+	id := d.id()
+	if id == "" {
+		return nil, d.errorf("unexpected %q", d.src[d.pos])
+	}
+	return id, nil
+}
+
+func (d *risonDecoder) object() (map[string]any, error) {
+	// Skip the (
+	d.pos++
+	obj := map[string]any{}
+	for {
+		if d.pos >= len(d.src) {
+			return nil, d.errorf("missing ) to close the object")
+		}
+		if d.src[d.pos] == ')' {
+			d.pos++
+			return obj, nil
+		}
+		if len(obj) > 0 {
+			if d.src[d.pos] != ',' {
+				return nil, d.errorf("expected , between the properties of an object")
+			}
+			d.pos++
+		}
+
+		key, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		k, ok := key.(string)
+		if !ok {
+			return nil, d.errorf("object keys must be strings but got %v", key)
+		}
+		if d.pos >= len(d.src) || d.src[d.pos] != ':' {
+			return nil, d.errorf("expected : after the key %v", k)
+		}
+		d.pos++
+		v, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		obj[k] = v
+	}
+}
+
+// array decodes the elements of an array; the leading !( has already been consumed.
+func (d *risonDecoder) array() ([]any, error) {
+	arr := []any{}
+	for {
+		if d.pos >= len(d.src) {
+			return nil, d.errorf("missing ) to close the array")
+		}
+		if d.src[d.pos] == ')' {
+			d.pos++
+			return arr, nil
+		}
+		if len(arr) > 0 {
+			if d.src[d.pos] != ',' {
+				return nil, d.errorf("expected , between the elements of an array")
+			}
+			d.pos++
+		}
+		v, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, v)
+	}
+}
+
+func (d *risonDecoder) quoted() (string, error) {
+	start := d.pos
+	d.pos++
+	var sb strings.Builder
+	for d.pos < len(d.src) {
+		c := d.src[d.pos]
+		d.pos++
+		switch c {
+		case '\'':
+			return sb.String(), nil
+		case '!':
+			if d.pos >= len(d.src) {
+				break
+			}
+			escaped := d.src[d.pos]
+			if escaped != '\'' && escaped != '!' {
+				return "", d.errorf("invalid escape !%c", escaped)
+			}
+			sb.WriteByte(escaped)
+			d.pos++
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	d.pos = start
+	return "", d.errorf("unterminated string")
+}
+
+func (d *risonDecoder) number() (float64, error) {
+	start := d.pos
+	for d.pos < len(d.src) && strings.IndexByte("0123456789.-+eE", d.src[d.pos]) >= 0 {
+		d.pos++
+	}
+	raw := d.src[start:d.pos]
+	f, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		d.pos = start
+		return 0, d.errorf("invalid number %v", raw)
+	}
+	return f, nil
+}
+
+func (d *risonDecoder) id() string {
+	start := d.pos
+	for d.pos < len(d.src) && strings.IndexByte(risonNotIDChars, d.src[d.pos]) < 0 {
+		d.pos++
+	}
+	return d.src[start:d.pos]
+}
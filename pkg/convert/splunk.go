@@ -0,0 +1,522 @@
+package convert
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jlewi/ddctl/api"
+	"github.com/jlewi/ddctl/pkg/ddog"
+	"github.com/jlewi/ddctl/pkg/query"
+	"github.com/pkg/errors"
+)
+
+var (
+	// DefaultSplunkFieldMapping maps Splunk default fields to the equivalent Datadog tags and reserved attributes.
+	DefaultSplunkFieldMapping = map[string]string{
+		"sourcetype": "source",
+		"host":       "host",
+		"service":    "service",
+		"env":        "env",
+		"status":     "status",
+	}
+
+	// splunkAggTypes maps Splunk stats functions to Datadog aggregation types
+	splunkAggTypes = map[string]string{
+		"count":  "count",
+		"dc":     "cardinality",
+		"sum":    "sum",
+		"avg":    "avg",
+		"mean":   "avg",
+		"min":    "min",
+		"max":    "max",
+		"median": "median",
+		"p75":    "pc75",
+		"p90":    "pc90",
+		"p95":    "pc95",
+		"p98":    "pc98",
+		"p99":    "pc99",
+		"perc75": "pc75",
+		"perc90": "pc90",
+		"perc95": "pc95",
+		"perc98": "pc98",
+		"perc99": "pc99",
+	}
+
+	// splunkComparisonRegex matches a field comparison e.g. status>=500 or host="web 01"
+	splunkComparisonRegex = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_.:@-]*)(!=|>=|<=|=|>|<)(.*)$`)
+
+	// splunkStatsRegex matches a stats or timechart aggregation e.g. count, avg(duration) or p99(latency)
+	splunkStatsRegex = regexp.MustCompile(`^([a-z0-9_]+)(?:\(([^)]*)\))?$`)
+
+	// splunkRelativeTimeRegex matches a relative time modifier e.g. -24h or -7d@d
+	splunkRelativeTimeRegex = regexp.MustCompile(`^-(\d+)([a-z]+)(@[a-z0-9]+)?$`)
+
+	// splunkTimeUnits maps Splunk relative time units to Grafana style units
+	splunkTimeUnits = map[string]string{
+		"s": "s", "sec": "s", "secs": "s", "second": "s", "seconds": "s",
+		"m": "m", "min": "m", "mins": "m", "minute": "m", "minutes": "m",
+		"h": "h", "hr": "h", "hrs": "h", "hour": "h", "hours": "h",
+		"d": "d", "day": "d", "days": "d",
+		"w": "w", "week": "w", "weeks": "w",
+		"mon": "M", "month": "M", "months": "M",
+		"y": "y", "yr": "y", "yrs": "y", "year": "y", "years": "y",
+	}
+)
+
+// SplunkOptions configures converting Splunk searches.
+type SplunkOptions struct {
+	// BaseURL is the base URL of the Datadog link e.g. https://app.datadoghq.com
+	BaseURL string
+	// FieldMapping maps Splunk fields to Datadog fields. It extends DefaultSplunkFieldMapping.
+	// Fields that aren't mapped become facets e.g. user becomes @user.
+	FieldMapping map[string]string
+}
+
+// SplunkToLink translates a Splunk search into a DatadogLink.
+//
+// The supported subset is the search command (field comparisons, free text, wildcards, NOT, OR, AND and
+// parentheses), index and sourcetype, the earliest and latest time modifiers, stats and timechart aggregations
+// with a by clause, and table and fields which become the columns. Other commands are reported as warnings
+// with their position in the search.
+func SplunkToLink(spl string, opts SplunkOptions) (*api.DatadogLink, []Warning, error) {
+	c := &splunkConverter{
+		src:    spl,
+		fields: MergeMappings(DefaultSplunkFieldMapping, opts.FieldMapping),
+		b:      ddog.Q(),
+		link: &api.DatadogLink{
+			APIVersion: api.LinkGVK.GroupVersion().String(),
+			Kind:       api.LinkGVK.Kind,
+			BaseURL:    opts.BaseURL,
+		},
+	}
+
+	for i, cmd := range splitPipeline(spl) {
+		if err := c.convertCommand(cmd, i == 0); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	c.link.Query = c.b.String()
+	return c.link, c.warnings, nil
+}
+
+// splunkToken is a token in a Splunk search and its byte offset in the search.
+type splunkToken struct {
+	text string
+	pos  int
+}
+
+type splunkConverter struct {
+	src      string
+	fields   map[string]string
+	b        *ddog.QueryBuilder
+	link     *api.DatadogLink
+	warnings []Warning
+
+	// tokens and next are the tokens of the search command being parsed
+	tokens []splunkToken
+	next   int
+}
+
+func (c *splunkConverter) warn(pos int, construct string, format string, args ...any) {
+	c.warnings = append(c.warnings, warnAt(c.src, pos, construct, format, args...))
+}
+
+func (c *splunkConverter) errorf(pos int, format string, args ...any) error {
+	return errors.Errorf("invalid search at column %d: %v", pos+1, errors.Errorf(format, args...))
+}
+
+// field returns the Datadog field for a Splunk field.
+func (c *splunkConverter) field(name string) string {
+	if f, ok := c.fields[name]; ok {
+		return f
+	}
+	return "@" + name
+}
+
+// splitPipeline splits the search into its commands on the | characters that aren't quoted.
+func splitPipeline(spl string) []splunkToken {
+	var cmds []splunkToken
+	start := 0
+	inQuote := false
+	for i := 0; i < len(spl); i++ {
+		switch spl[i] {
+		case '\\':
+			i++
+		case '"':
+			inQuote = !inQuote
+		case '|':
+			if !inQuote {
+				cmds = append(cmds, splunkToken{text: spl[start:i], pos: start})
+				start = i + 1
+			}
+		}
+	}
+	return append(cmds, splunkToken{text: spl[start:], pos: start})
+}
+
+// tokenize splits a command into words, quoted phrases and, if parens is true, parentheses. Quotes inside a word
+// (e.g. msg="connection reset") are part of the word.
+func tokenize(cmd splunkToken, parens bool) []splunkToken {
+	delims := " \t\n\r,"
+	if parens {
+		delims += "()"
+	}
+	var tokens []splunkToken
+	s := cmd.text
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			i++
+		case parens && (c == '(' || c == ')'):
+			tokens = append(tokens, splunkToken{text: string(c), pos: cmd.pos + i})
+			i++
+		default:
+			start := i
+			inQuote := false
+			for ; i < len(s); i++ {
+				c := s[i]
+				if c == '\\' {
+					i++
+					continue
+				}
+				if c == '"' {
+					inQuote = !inQuote
+					continue
+				}
+				if !inQuote && strings.ContainsRune(delims, rune(c)) {
+					break
+				}
+			}
+			tokens = append(tokens, splunkToken{text: s[start:min(i, len(s))], pos: cmd.pos + start})
+		}
+	}
+	return tokens
+}
+
+func (c *splunkConverter) convertCommand(cmd splunkToken, first bool) error {
+	tokens := tokenize(cmd, false)
+	if len(tokens) == 0 {
+		if first {
+			// The search starts with a generating command e.g. | tstats
+			return nil
+		}
+		return c.errorf(cmd.pos, "empty command")
+	}
+
+	name := strings.ToLower(tokens[0].text)
+	if first && name != "search" {
+		// The first command is an implicit search
+		return c.convertSearch(tokenize(cmd, true))
+	}
+
+	end := cmd.pos + len(strings.TrimRight(cmd.text, " \t\n\r"))
+	construct := strings.TrimSpace(c.src[tokens[0].pos:end])
+	switch name {
+	case "search":
+		return c.convertSearch(tokenize(cmd, true)[1:])
+	case "stats":
+		c.convertStats(tokens, construct, "toplist")
+	case "timechart":
+		c.convertStats(tokens, construct, "timeseries")
+	case "table", "fields":
+		for _, t := range tokens[1:] {
+			switch {
+			case t.text == "+":
+			case t.text == "-":
+				c.warn(t.pos, construct, "removing fields isn't supported")
+				return nil
+			case strings.HasPrefix(t.text, "_"):
+				// Internal fields such as _time and _raw are always displayed by Datadog
+			default:
+				c.link.Columns = append(c.link.Columns, c.field(t.text))
+			}
+		}
+	default:
+		c.warn(tokens[0].pos, construct, "the %v command isn't supported", name)
+	}
+	return nil
+}
+
+// convertStats translates stats and timechart e.g. stats count by host into the aggregation of the link.
+func (c *splunkConverter) convertStats(tokens []splunkToken, construct string, viz string) {
+	var aggs []splunkToken
+	var by []splunkToken
+	inBy := false
+	for _, t := range tokens[1:] {
+		switch {
+		case strings.EqualFold(t.text, "by"):
+			inBy = true
+		case inBy:
+			by = append(by, t)
+		case strings.Contains(t.text, "="):
+			// Options such as span=1h
+			c.warn(t.pos, t.text, "%v options aren't supported", tokens[0].text)
+		default:
+			aggs = append(aggs, t)
+		}
+	}
+
+	if len(aggs) == 0 {
+		c.warn(tokens[0].pos, construct, "expected an aggregation e.g. count")
+		return
+	}
+
+	for i, agg := range aggs {
+		if i > 0 {
+			c.warn(agg.pos, agg.text, "only the first aggregation is translated")
+			continue
+		}
+
+		m := splunkStatsRegex.FindStringSubmatch(strings.ToLower(agg.text))
+		aggType, ok := "", false
+		if m != nil {
+			aggType, ok = splunkAggTypes[m[1]]
+		}
+		if !ok {
+			c.warn(agg.pos, agg.text, "the aggregation isn't supported")
+			return
+		}
+
+		// Use the original text of the field to preserve its case
+		measure := "count"
+		if open := strings.Index(agg.text, "("); open >= 0 && m[2] != "" {
+			measure = c.field(strings.TrimSuffix(agg.text[open+1:], ")"))
+		}
+		c.link.GroupInto = measure
+		c.link.AggType = aggType
+	}
+
+	groupBy := make([]string, 0, len(by))
+	for _, t := range by {
+		groupBy = append(groupBy, c.field(t.text))
+	}
+	c.link.GroupBy = strings.Join(groupBy, ",")
+	c.link.VisualizeAs = viz
+}
+
+func (c *splunkConverter) convertSearch(tokens []splunkToken) error {
+	c.tokens = tokens
+	c.next = 0
+	for c.next < len(c.tokens) {
+		n, err := c.parseOr()
+		if err != nil {
+			return err
+		}
+		c.b.Term(n)
+		if c.next < len(c.tokens) && c.tokens[c.next].text == ")" {
+			return c.errorf(c.tokens[c.next].pos, "unexpected )")
+		}
+	}
+	return nil
+}
+
+func (c *splunkConverter) peek() string {
+	if c.next >= len(c.tokens) {
+		return ""
+	}
+	return c.tokens[c.next].text
+}
+
+func (c *splunkConverter) parseOr() (query.Node, error) {
+	var operands []query.Node
+	for {
+		n, err := c.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		if n != nil {
+			operands = append(operands, n)
+		}
+		if c.peek() != "OR" {
+			break
+		}
+		c.next++
+	}
+
+	switch len(operands) {
+	case 0:
+		return nil, nil
+	case 1:
+		return operands[0], nil
+	}
+	return &query.Or{Operands: operands}, nil
+}
+
+func (c *splunkConverter) parseAnd() (query.Node, error) {
+	var operands []query.Node
+	for c.next < len(c.tokens) {
+		switch c.peek() {
+		case "OR", ")":
+			return andOf(operands), nil
+		case "AND":
+			c.next++
+			continue
+		}
+
+		n, err := c.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if n != nil {
+			operands = append(operands, n)
+		}
+	}
+	return andOf(operands), nil
+}
+
+func andOf(operands []query.Node) query.Node {
+	switch len(operands) {
+	case 0:
+		return nil
+	case 1:
+		return operands[0]
+	}
+	return &query.And{Operands: operands, Implicit: true}
+}
+
+func (c *splunkConverter) parseUnary() (query.Node, error) {
+	t := c.tokens[c.next]
+	switch t.text {
+	case "NOT":
+		c.next++
+		if c.next >= len(c.tokens) {
+			return nil, c.errorf(t.pos, "expected a term after NOT")
+		}
+		n, err := c.parseUnary()
+		if err != nil || n == nil {
+			return nil, err
+		}
+		return &query.Not{Operand: n}, nil
+	case "(":
+		c.next++
+		n, err := c.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if c.peek() != ")" {
+			return nil, c.errorf(t.pos, "missing ) to close (")
+		}
+		c.next++
+		return n, nil
+	}
+	c.next++
+	return c.convertTerm(t), nil
+}
+
+// convertTerm translates a search term. It returns nil for terms that don't filter the logs e.g. earliest=-1h.
+func (c *splunkConverter) convertTerm(t splunkToken) query.Node {
+	m := splunkComparisonRegex.FindStringSubmatch(t.text)
+	if m == nil {
+		return splunkValue(t.text)
+	}
+
+	name, op, value := m[1], m[2], unquote(m[3])
+	switch strings.ToLower(name) {
+	case "earliest":
+		c.link.FromTS = c.convertTime(t, value)
+		return nil
+	case "latest":
+		c.link.ToTS = c.convertTime(t, value)
+		return nil
+	case "index":
+		if op == "=" && !strings.Contains(value, "*") && c.inTopLevelAnd() {
+			c.link.Indexes = append(c.link.Indexes, value)
+		} else {
+			c.warn(t.pos, t.text, "only index=<name> ANDed with the rest of the search is translated")
+		}
+		return nil
+	}
+
+	key := c.field(name)
+	switch op {
+	case "=":
+		return &query.Field{Key: key, Value: splunkValue(m[3])}
+	case "!=":
+		return &query.Not{Operand: &query.Field{Key: key, Value: splunkValue(m[3])}}
+	}
+
+	if _, err := strconv.ParseFloat(value, 64); err != nil {
+		c.warn(t.pos, t.text, "only numeric comparisons are supported")
+		return nil
+	}
+	return &query.Field{Key: key, Value: &query.Comparison{Op: op, Value: value}}
+}
+
+// inTopLevelAnd returns true if the token just consumed is ANDed with the rest of the search i.e. it isn't
+// inside parentheses or negated and the search has no top level OR.
+func (c *splunkConverter) inTopLevelAnd() bool {
+	current := c.next - 1
+	if current > 0 && c.tokens[current-1].text == "NOT" {
+		return false
+	}
+
+	depth := 0
+	for i, t := range c.tokens {
+		switch t.text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+		case "OR":
+			if depth == 0 {
+				return false
+			}
+		}
+		if i == current && depth != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// convertTime translates the value of earliest or latest into a link timestamp.
+func (c *splunkConverter) convertTime(t splunkToken, value string) string {
+	switch value {
+	case "now", "now()":
+		return "now"
+	case "0":
+		c.warn(t.pos, t.text, "all time isn't supported; the link uses the default time range")
+		return ""
+	}
+
+	if secs, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return strconv.FormatInt(secs*1000, 10)
+	}
+
+	m := splunkRelativeTimeRegex.FindStringSubmatch(value)
+	if m == nil {
+		c.warn(t.pos, t.text, "only relative times (e.g. -24h) and epoch seconds are supported")
+		return ""
+	}
+	unit, ok := splunkTimeUnits[m[2]]
+	if !ok {
+		c.warn(t.pos, t.text, "unknown time unit %v", m[2])
+		return ""
+	}
+	if m[3] != "" {
+		c.warn(t.pos, t.text, "snapping to %v isn't supported", m[3])
+	}
+	return "now-" + m[1] + unit
+}
+
+// splunkValue returns the Datadog value for a Splunk value. Wildcards in Splunk work inside quotes too.
+func splunkValue(raw string) *query.Text {
+	value := unquote(raw)
+	if strings.Contains(value, "*") {
+		return query.Wildcard(value)
+	}
+	return query.Literal(value)
+}
+
+// unquote removes the quotes around a value.
+func unquote(value string) string {
+	if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+		if v, err := strconv.Unquote(value); err == nil {
+			return v
+		}
+		return value[1 : len(value)-1]
+	}
+	return value
+}
@@ -0,0 +1,108 @@
+package convert
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jlewi/ddctl/api"
+)
+
+func TestSplunkToLink(t *testing.T) {
+	type testCase struct {
+		Name             string
+		Input            string
+		Mapping          map[string]string
+		Expected         *api.DatadogLink
+		ExpectedWarnings []string
+	}
+
+	cases := []testCase{
+		{
+			Name:  "filters",
+			Input: `index=main sourcetype=nginx status>=500 NOT host=web01 (method=GET OR method=POST) "connection reset" uri=/api/* earliest=-24h latest=now`,
+			Expected: &api.DatadogLink{
+				Indexes: []string{"main"},
+				Query:   `source:nginx status:>=500 -host:web01 (@method:GET OR @method:POST) "connection reset" @uri:\/api\/*`,
+				FromTS:  "now-24h",
+				ToTS:    "now",
+			},
+		},
+		{
+			Name:  "stats",
+			Input: `search index=main error | stats p99(duration) count by host, user | sort -count`,
+			Expected: &api.DatadogLink{
+				Indexes:     []string{"main"},
+				Query:       "error",
+				VisualizeAs: "toplist",
+				GroupInto:   "@duration",
+				AggType:     "pc99",
+				GroupBy:     "host,@user",
+			},
+			ExpectedWarnings: []string{
+				"column 47: count: only the first aggregation is translated",
+				"column 69: sort -count: the sort command isn't supported",
+			},
+		},
+		{
+			Name:    "timechart-and-table",
+			Input:   `sourcetype=app level=error earliest=-7d@d | timechart count by app | table _time app message`,
+			Mapping: map[string]string{"level": "status", "app": "service"},
+			Expected: &api.DatadogLink{
+				Query:       "source:app status:error",
+				FromTS:      "now-7d",
+				VisualizeAs: "timeseries",
+				GroupInto:   "count",
+				AggType:     "count",
+				GroupBy:     "service",
+				Columns:     []string{"service", "@message"},
+			},
+			ExpectedWarnings: []string{
+				"column 28: earliest=-7d@d: snapping to @d isn't supported",
+			},
+		},
+		{
+			Name:  "unsupported",
+			Input: `index=a OR index=b user=*admin* | rex field=_raw "id=(?<id>\d+)" | search id!=0`,
+			Expected: &api.DatadogLink{
+				Query: "@user:*admin* -@id:0",
+			},
+			ExpectedWarnings: []string{
+				"column 1: index=a: only index=<name> ANDed with the rest of the search is translated",
+				"column 12: index=b: only index=<name> ANDed with the rest of the search is translated",
+				`column 35: rex field=_raw "id=(?<id>\d+)": the rex command isn't supported`,
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			actual, warnings, err := SplunkToLink(c.Input, SplunkOptions{BaseURL: "https://acme.datadoghq.com", FieldMapping: c.Mapping})
+			if err != nil {
+				t.Fatalf("Failed to convert %v: %+v", c.Input, err)
+			}
+
+			c.Expected.APIVersion = api.LinkGVK.GroupVersion().String()
+			c.Expected.Kind = api.LinkGVK.Kind
+			c.Expected.BaseURL = "https://acme.datadoghq.com"
+			if d := cmp.Diff(c.Expected, actual); d != "" {
+				t.Errorf("Link doesn't match; diff\n%v", d)
+			}
+
+			var actualWarnings []string
+			for _, w := range warnings {
+				actualWarnings = append(actualWarnings, w.String())
+			}
+			if d := cmp.Diff(c.ExpectedWarnings, actualWarnings); d != "" {
+				t.Errorf("Warnings don't match; diff\n%v", d)
+			}
+		})
+	}
+}
+
+func TestSplunkToLink_Errors(t *testing.T) {
+	for _, input := range []string{`a (b OR c`, `a b)`, `error NOT`} {
+		if _, _, err := SplunkToLink(input, SplunkOptions{}); err == nil {
+			t.Errorf("Expected an error converting %v", input)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package ddog
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/jlewi/ddctl/api"
+)
+
+const (
+	auditTrailPath = "/audit-trail"
+)
+
+var (
+	// logsOnlyParams are the Log Explorer query parameters that the Audit Trail explorer doesn't support.
+	logsOnlyParams = []string{"storage", "clustering_pattern_field_path", "saved-view-id", "index"}
+)
+
+// BuildAuditURL builds the URL for an Audit Trail link.
+func BuildAuditURL(link *api.DatadogAudit) (string, error) {
+	queryParams, err := logsQueryParams(auditToLogsLink(link))
+	if err != nil {
+		return "", err
+	}
+	addExtraParams(queryParams, link.ExtraParams)
+
+	encodedQuery := queryParams.Encode()
+	u := fmt.Sprintf("%s%s?%s", link.BaseURL, auditTrailPath, encodedQuery)
+	return u, nil
+}
+
+// AuditURLToLink converts an Audit Trail URL to a DatadogAudit link.
+func AuditURLToLink(u url.URL) (*api.DatadogAudit, error) {
+	logsLink := &api.DatadogLink{}
+	queryParamMap := logsQueryParamMap(logsLink)
+	for _, key := range logsOnlyParams {
+		delete(queryParamMap, key)
+	}
+	extraParams := bindQueryParams(u.Query(), queryParamMap)
+
+	link := &api.DatadogAudit{
+		APIVersion:     api.AuditGVK.GroupVersion().String(),
+		Kind:           api.AuditGVK.Kind,
+		BaseURL:        getBaseURL(u),
+		Query:          logsLink.Query,
+		VisualizeAs:    logsLink.VisualizeAs,
+		GroupInto:      logsLink.GroupInto,
+		Missing:        logsLink.Missing,
+		TopN:           logsLink.TopN,
+		Source:         logsLink.Source,
+		GroupBy:        logsLink.GroupBy,
+		MessageDisplay: logsLink.MessageDisplay,
+		StreamSort:     logsLink.StreamSort,
+		Live:           logsLink.Live,
+		TopO:           logsLink.TopO,
+		GroupBySource:  logsLink.GroupBySource,
+		AggType:        logsLink.AggType,
+		Columns:        logsLink.Columns,
+		RefreshMode:    logsLink.RefreshMode,
+		FromTS:         logsLink.FromTS,
+		ToTS:           logsLink.ToTS,
+		FromUser:       logsLink.FromUser,
+		ExtraParams:    extraParams,
+	}
+	return link, nil
+}
+
+// auditToLogsLink converts the link to a DatadogLink so it can share the logic for building the query parameters.
+func auditToLogsLink(link *api.DatadogAudit) *api.DatadogLink {
+	return &api.DatadogLink{
+		BaseURL:        link.BaseURL,
+		Query:          link.Query,
+		VisualizeAs:    link.VisualizeAs,
+		GroupInto:      link.GroupInto,
+		Missing:        link.Missing,
+		TopN:           link.TopN,
+		Source:         link.Source,
+		GroupBy:        link.GroupBy,
+		MessageDisplay: link.MessageDisplay,
+		StreamSort:     link.StreamSort,
+		Live:           link.Live,
+		TopO:           link.TopO,
+		GroupBySource:  link.GroupBySource,
+		AggType:        link.AggType,
+		Columns:        link.Columns,
+		RefreshMode:    link.RefreshMode,
+		FromTS:         link.FromTS,
+		ToTS:           link.ToTS,
+		FromUser:       link.FromUser,
+	}
+}
@@ -0,0 +1,54 @@
+package ddog
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/jlewi/ddctl/api"
+	"github.com/pkg/errors"
+)
+
+const (
+	servicesPath = "/services"
+)
+
+// BuildCatalogEntityURL builds the URL for a Software Catalog entity link.
+func BuildCatalogEntityURL(link *api.DatadogCatalogEntity) (string, error) {
+	if link.Service == "" {
+		return "", errors.New("service must be set")
+	}
+
+	queryParams := url.Values{}
+	addString(queryParams, "tab", link.Tab)
+	addString(queryParams, "env", link.Env)
+	addExtraParams(queryParams, link.ExtraParams)
+
+	u := fmt.Sprintf("%s%s/%s", link.BaseURL, servicesPath, url.PathEscape(link.Service))
+	if encodedQuery := queryParams.Encode(); encodedQuery != "" {
+		u = u + "?" + encodedQuery
+	}
+	return u, nil
+}
+
+// CatalogEntityURLToLink converts a Software Catalog URL to a DatadogCatalogEntity link.
+func CatalogEntityURLToLink(u url.URL) (*api.DatadogCatalogEntity, error) {
+	link := &api.DatadogCatalogEntity{
+		APIVersion: api.CatalogEntityGVK.GroupVersion().String(),
+		Kind:       api.CatalogEntityGVK.Kind,
+		BaseURL:    getBaseURL(u),
+	}
+
+	link.Service = strings.Trim(strings.TrimPrefix(u.Path, servicesPath), "/")
+	if link.Service == "" {
+		return nil, errors.Errorf("URL %v doesn't contain a service; expected a path like %v/<service>", u.String(), servicesPath)
+	}
+
+	queryParamMap := map[string]queryValHandler{
+		"tab": bindToString(&link.Tab),
+		"env": bindToString(&link.Env),
+	}
+
+	link.ExtraParams = bindQueryParams(u.Query(), queryParamMap)
+	return link, nil
+}
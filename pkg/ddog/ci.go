@@ -0,0 +1,77 @@
+package ddog
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/jlewi/ddctl/api"
+	"github.com/pkg/errors"
+)
+
+const (
+	ciPath = "/ci"
+)
+
+var (
+	ciPages = []string{api.CIPagePipelineExecutions, api.CIPageTestRuns, api.CIPageFlakyTests}
+)
+
+// ciFacets returns the query facets for the structured fields of the link.
+func ciFacets(link *api.DatadogCI) []facetField {
+	return []facetField{
+		{key: "@git.repository.id", field: &link.Repository},
+		{key: "@git.branch", field: &link.Branch},
+		{key: "@ci.pipeline.name", field: &link.PipelineName},
+		{key: "@git.commit.sha", field: &link.CommitSHA},
+	}
+}
+
+// BuildCIURL builds the URL for a CI Visibility link.
+func BuildCIURL(link *api.DatadogCI) (string, error) {
+	page := link.Page
+	if page == "" {
+		page = api.CIPagePipelineExecutions
+	}
+
+	if !slices.Contains(ciPages, page) {
+		return "", errors.Errorf("Unsupported CI Visibility page %v; supported pages are %v", page, ciPages)
+	}
+
+	queryParams := url.Values{}
+	addString(queryParams, "query", buildFacetQuery(ciFacets(link), link.Query))
+	if err := addTimeRange(queryParams, link.FromTS, link.ToTS); err != nil {
+		return "", err
+	}
+	addExtraParams(queryParams, link.ExtraParams)
+
+	encodedQuery := queryParams.Encode()
+	u := fmt.Sprintf("%s%s/%s?%s", link.BaseURL, ciPath, page, encodedQuery)
+	return u, nil
+}
+
+// CIURLToLink converts a CI Visibility URL to a DatadogCI link.
+func CIURLToLink(u url.URL) (*api.DatadogCI, error) {
+	link := &api.DatadogCI{
+		APIVersion: api.CIGVK.GroupVersion().String(),
+		Kind:       api.CIGVK.Kind,
+		BaseURL:    getBaseURL(u),
+	}
+
+	page := strings.Trim(strings.TrimPrefix(u.Path, ciPath), "/")
+	if !slices.Contains(ciPages, page) {
+		return nil, errors.Errorf("Unsupported CI Visibility page %v; supported pages are %v", page, ciPages)
+	}
+	link.Page = page
+
+	queryParamMap := map[string]queryValHandler{
+		"query":   bindToString(&link.Query),
+		"from_ts": bindToString(&link.FromTS),
+		"to_ts":   bindToString(&link.ToTS),
+	}
+
+	link.ExtraParams = bindQueryParams(u.Query(), queryParamMap)
+	link.Query = extractFacets(ciFacets(link), link.Query)
+	return link, nil
+}
@@ -0,0 +1,77 @@
+package ddog
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/jlewi/ddctl/api"
+	"github.com/pkg/errors"
+)
+
+const (
+	databasesPath = "/databases"
+)
+
+var (
+	databasePages = []string{api.DatabasePageQueries, api.DatabasePageSamples, api.DatabasePageExplainPlans, api.DatabasePageHosts}
+)
+
+// BuildDatabaseURL builds the URL for a Database Monitoring link.
+func BuildDatabaseURL(link *api.DatadogDatabase) (string, error) {
+	page := link.Page
+	if page == "" {
+		page = api.DatabasePageQueries
+	}
+
+	if !slices.Contains(databasePages, page) {
+		return "", errors.Errorf("Unsupported Database Monitoring page %v; supported pages are %v", page, databasePages)
+	}
+
+	queryParams := url.Values{}
+	addString(queryParams, "query", link.Query)
+	addString(queryParams, "query_signature", link.QuerySignature)
+	addString(queryParams, "host", link.Host)
+	addString(queryParams, "dbms", link.DBMS)
+	addString(queryParams, "sort", link.Sort)
+	if err := addTimeRange(queryParams, link.FromTS, link.ToTS); err != nil {
+		return "", err
+	}
+	addExtraParams(queryParams, link.ExtraParams)
+
+	encodedQuery := queryParams.Encode()
+	u := fmt.Sprintf("%s%s/%s?%s", link.BaseURL, databasesPath, page, encodedQuery)
+	return u, nil
+}
+
+// DatabaseURLToLink converts a Database Monitoring URL to a DatadogDatabase link.
+func DatabaseURLToLink(u url.URL) (*api.DatadogDatabase, error) {
+	link := &api.DatadogDatabase{
+		APIVersion: api.DatabaseGVK.GroupVersion().String(),
+		Kind:       api.DatabaseGVK.Kind,
+		BaseURL:    getBaseURL(u),
+	}
+
+	page := strings.Trim(strings.TrimPrefix(u.Path, databasesPath), "/")
+	if page == "" {
+		page = api.DatabasePageQueries
+	}
+	if !slices.Contains(databasePages, page) {
+		return nil, errors.Errorf("Unsupported Database Monitoring page %v; supported pages are %v", page, databasePages)
+	}
+	link.Page = page
+
+	queryParamMap := map[string]queryValHandler{
+		"query":           bindToString(&link.Query),
+		"query_signature": bindToString(&link.QuerySignature),
+		"host":            bindToString(&link.Host),
+		"dbms":            bindToString(&link.DBMS),
+		"sort":            bindToString(&link.Sort),
+		"from_ts":         bindToString(&link.FromTS),
+		"to_ts":           bindToString(&link.ToTS),
+	}
+
+	link.ExtraParams = bindQueryParams(u.Query(), queryParamMap)
+	return link, nil
+}
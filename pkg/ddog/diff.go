@@ -0,0 +1,381 @@
+package ddog
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jlewi/ddctl/pkg/query"
+	"github.com/pkg/errors"
+	yaml "sigs.k8s.io/yaml/goyaml.v3"
+)
+
+var (
+	// diffIgnoredFields are the top level fields that don't affect what a link shows
+	diffIgnoredFields = map[string]bool{"apiVersion": true, "metadata": true}
+)
+
+// LinkDiff is the difference between two links.
+type LinkDiff struct {
+	// TimeWindow is the change to the time window or nil if it didn't change
+	TimeWindow *TimeWindowChange `json:"timeWindow,omitempty"`
+	// Changes are the other fields that changed ordered by field
+	Changes []FieldChange `json:"changes,omitempty"`
+}
+
+// Empty returns true if the links are equivalent.
+func (d *LinkDiff) Empty() bool {
+	return d.TimeWindow == nil && len(d.Changes) == 0
+}
+
+// FieldChange is a change to a field of a link.
+type FieldChange struct {
+	// Field is the path of the field in the YAML representation of the link e.g. viz or queries[0].measure
+	Field string `json:"field"`
+	// From and To are the values of the field. They are omitted for lists and queries; see Added and Removed.
+	From any `json:"from,omitempty"`
+	To   any `json:"to,omitempty"`
+	// Added and Removed are the elements of a list, or the clauses of a query, that were added and removed
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+	// Reordered is true if a list has the same elements in a different order
+	Reordered bool `json:"reordered,omitempty"`
+}
+
+func (c FieldChange) String() string {
+	if c.Reordered {
+		return fmt.Sprintf("%v: reordered", c.Field)
+	}
+	if c.Added != nil || c.Removed != nil {
+		var parts []string
+		for _, a := range c.Added {
+			parts = append(parts, "+"+a)
+		}
+		for _, r := range c.Removed {
+			parts = append(parts, "-"+r)
+		}
+		return fmt.Sprintf("%v: %v", c.Field, strings.Join(parts, " "))
+	}
+	return fmt.Sprintf("%v: %v -> %v", c.Field, diffValue(c.From), diffValue(c.To))
+}
+
+// TimeWindowChange is a change to the time window of a link.
+type TimeWindowChange struct {
+	From FieldChange `json:"from"`
+	To   FieldChange `json:"to"`
+	// StartShift and EndShift are how far the start and end of the window moved e.g. -1h0m0s. They are empty
+	// if the times can't be compared.
+	StartShift string `json:"startShift,omitempty"`
+	EndShift   string `json:"endShift,omitempty"`
+	// DurationA and DurationB are the lengths of the windows if both ends are set
+	DurationA string `json:"durationA,omitempty"`
+	DurationB string `json:"durationB,omitempty"`
+}
+
+func (c TimeWindowChange) String() string {
+	s := fmt.Sprintf("time window: %v..%v -> %v..%v", diffValue(c.From.From), diffValue(c.To.From), diffValue(c.From.To), diffValue(c.To.To))
+	var details []string
+	if c.StartShift != "" {
+		details = append(details, "start shifted "+c.StartShift)
+	}
+	if c.EndShift != "" {
+		details = append(details, "end shifted "+c.EndShift)
+	}
+	if c.DurationA != "" && c.DurationB != "" && c.DurationA != c.DurationB {
+		details = append(details, fmt.Sprintf("duration %v -> %v", c.DurationA, c.DurationB))
+	}
+	if len(details) > 0 {
+		s += " (" + strings.Join(details, ", ") + ")"
+	}
+	return s
+}
+
+// diffValue returns the string for a value in a change.
+func diffValue(v any) string {
+	if v == nil {
+		return "(none)"
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// DiffLinks returns the field level difference between two links of any kind. Queries are compared clause by
+// clause so changes that don't affect the logs matched, such as reformatting, aren't reported. Lists such as
+// columns report the elements added and removed.
+func DiffLinks(a any, b any) (*LinkDiff, error) {
+	mapA, err := linkToMap(a)
+	if err != nil {
+		return nil, err
+	}
+	mapB, err := linkToMap(b)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &differ{queryFields: map[string]bool{}}
+	for _, link := range []any{a, b} {
+		fields, err := LinkQueryFields(link)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range fields {
+			d.queryFields[f.Name] = true
+		}
+	}
+
+	diff := &LinkDiff{}
+	diff.TimeWindow = diffTimeWindow(mapA, mapB)
+	for _, k := range []string{"fromTS", "toTS"} {
+		delete(mapA, k)
+		delete(mapB, k)
+	}
+	for k := range diffIgnoredFields {
+		delete(mapA, k)
+		delete(mapB, k)
+	}
+
+	d.diff("", mapA, mapB)
+	diff.Changes = d.changes
+	return diff, nil
+}
+
+// linkToMap returns the YAML representation of the link as a map.
+func linkToMap(link any) (map[string]any, error) {
+	b, err := yaml.Marshal(link)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to marshal %T", link)
+	}
+	m := map[string]any{}
+	if err := yaml.Unmarshal(b, &m); err != nil {
+		return nil, errors.Wrapf(err, "Failed to unmarshal %T", link)
+	}
+	return m, nil
+}
+
+type differ struct {
+	// queryFields are the names of the fields containing search queries
+	queryFields map[string]bool
+	changes     []FieldChange
+}
+
+func (d *differ) diff(path string, a any, b any) {
+	if reflect.DeepEqual(a, b) {
+		return
+	}
+
+	mapA, okA := a.(map[string]any)
+	mapB, okB := b.(map[string]any)
+	if okA && okB {
+		keys := map[string]bool{}
+		for k := range mapA {
+			keys[k] = true
+		}
+		for k := range mapB {
+			keys[k] = true
+		}
+		sorted := make([]string, 0, len(keys))
+		for k := range keys {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+		for _, k := range sorted {
+			d.diffField(joinPath(path, k), k, mapA[k], mapB[k])
+		}
+		return
+	}
+
+	listA, okA := asList(a)
+	listB, okB := asList(b)
+	if okA && okB {
+		if scalarsA, ok := scalars(listA); ok {
+			if scalarsB, ok := scalars(listB); ok {
+				added, removed := query.DiffStrings(scalarsA, scalarsB)
+				if len(added) == 0 && len(removed) == 0 {
+					// The lists have the same distinct elements but may repeat them a different number of times
+					added, removed = diffCounts(scalarsA, scalarsB)
+				}
+				d.changes = append(d.changes, FieldChange{Field: path, Added: added, Removed: removed, Reordered: len(added) == 0 && len(removed) == 0})
+				return
+			}
+		}
+		for i := 0; i < len(listA) || i < len(listB); i++ {
+			var elemA, elemB any
+			if i < len(listA) {
+				elemA = listA[i]
+			}
+			if i < len(listB) {
+				elemB = listB[i]
+			}
+			d.diff(fmt.Sprintf("%v[%d]", path, i), elemA, elemB)
+		}
+		return
+	}
+
+	d.changes = append(d.changes, FieldChange{Field: path, From: a, To: b})
+}
+
+// diffField compares a field; fields containing queries are compared clause by clause.
+func (d *differ) diffField(path string, key string, a any, b any) {
+	qa, okA := stringOrEmpty(a)
+	qb, okB := stringOrEmpty(b)
+	if !d.queryFields[key] || !okA || !okB {
+		d.diff(path, a, b)
+		return
+	}
+
+	added, removed, err := query.DiffClauses(qa, qb)
+	if err != nil {
+		// Fall back to comparing the strings
+		d.diff(path, a, b)
+		return
+	}
+	if len(added) == 0 && len(removed) == 0 {
+		return
+	}
+	d.changes = append(d.changes, FieldChange{Field: path, Added: added, Removed: removed})
+}
+
+// stringOrEmpty returns the value if it is a string; a missing value is the empty string.
+func stringOrEmpty(v any) (string, bool) {
+	if v == nil {
+		return "", true
+	}
+	s, ok := v.(string)
+	return s, ok
+}
+
+func joinPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// asList returns the value as a list; a missing value is an empty list.
+func asList(v any) ([]any, bool) {
+	if v == nil {
+		return nil, true
+	}
+	l, ok := v.([]any)
+	return l, ok
+}
+
+// scalars returns the list as strings if none of its elements are maps or lists.
+func scalars(l []any) ([]string, bool) {
+	s := make([]string, 0, len(l))
+	for _, e := range l {
+		switch e.(type) {
+		case map[string]any, []any:
+			return nil, false
+		}
+		s = append(s, fmt.Sprintf("%v", e))
+	}
+	return s, true
+}
+
+// diffCounts returns the elements that b repeats more times than a and the elements that a repeats more times
+// than b e.g. [a a b] and [a b] differ by one a.
+func diffCounts(a []string, b []string) ([]string, []string) {
+	counts := map[string]int{}
+	for _, s := range a {
+		counts[s]++
+	}
+	for _, s := range b {
+		counts[s]--
+	}
+
+	var added, removed []string
+	for _, s := range b {
+		if counts[s] < 0 {
+			added = append(added, s)
+			counts[s]++
+		}
+	}
+	for _, s := range a {
+		if counts[s] > 0 {
+			removed = append(removed, s)
+			counts[s]--
+		}
+	}
+	return added, removed
+}
+
+// diffTimeWindow compares the fromTS and toTS of the links.
+func diffTimeWindow(a map[string]any, b map[string]any) *TimeWindowChange {
+	fromA, _ := a["fromTS"].(string)
+	fromB, _ := b["fromTS"].(string)
+	toA, _ := a["toTS"].(string)
+	toB, _ := b["toTS"].(string)
+	if fromA == fromB && toA == toB {
+		return nil
+	}
+
+	c := &TimeWindowChange{
+		From: FieldChange{Field: "fromTS", From: optional(fromA), To: optional(fromB)},
+		To:   FieldChange{Field: "toTS", From: optional(toA), To: optional(toB)},
+	}
+
+	// Resolve relative times against the same instant so now-1h and now-2h differ by exactly an hour
+	now := time.Now()
+	startA, okStartA := linkTime(fromA, now)
+	startB, okStartB := linkTime(fromB, now)
+	endA, okEndA := linkTime(toA, now)
+	endB, okEndB := linkTime(toB, now)
+	if okStartA && okStartB && startA != startB {
+		c.StartShift = formatShift(startB.Sub(startA))
+	}
+	if okEndA && okEndB && endA != endB {
+		c.EndShift = formatShift(endB.Sub(endA))
+	}
+	if okStartA && okEndA {
+		c.DurationA = endA.Sub(startA).String()
+	}
+	if okStartB && okEndB {
+		c.DurationB = endB.Sub(startB).String()
+	}
+	return c
+}
+
+func optional(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// linkTime returns the time of a link timestamp; milliseconds since the epoch or a relative time such as now-1h.
+func linkTime(value string, now time.Time) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+	if strings.Contains(value, "now") {
+		parser := *timeParser
+		parser.Clock = fixedClock{now: now}
+		t, err := parser.ParseGrafanaRelativeTime(value)
+		return t, err == nil
+	}
+	ms, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.UnixMilli(ms), true
+}
+
+// formatShift formats a shift with an explicit sign e.g. +1h0m0s.
+func formatShift(d time.Duration) string {
+	if d > 0 {
+		return "+" + d.String()
+	}
+	return d.String()
+}
+
+// fixedClock is a clock that always returns the same time.
+type fixedClock struct {
+	now time.Time
+}
+
+func (c fixedClock) Now() time.Time {
+	return c.now
+}
@@ -0,0 +1,142 @@
+package ddog
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jlewi/ddctl/api"
+)
+
+func TestDiffLinks(t *testing.T) {
+	type testCase struct {
+		name     string
+		a        any
+		b        any
+		expected *LinkDiff
+	}
+
+	cases := []testCase{
+		{
+			name: "same",
+			a: &api.DatadogLink{
+				Metadata: api.Metadata{Name: "a"},
+				Query:    `service:web AND status:error`,
+				FromTS:   "now-1h",
+			},
+			b: &api.DatadogLink{
+				Metadata: api.Metadata{Name: "b"},
+				Query:    `status:error service:"web"`,
+				FromTS:   "now-1h",
+			},
+			expected: &LinkDiff{},
+		},
+		{
+			name: "fields",
+			a: &api.DatadogLink{
+				Query:       "service:web status:error",
+				VisualizeAs: "stream",
+				Columns:     []string{"host", "service"},
+				FromTS:      "now-1h",
+				ToTS:        "now",
+			},
+			b: &api.DatadogLink{
+				Query:       "status:error env:prod",
+				VisualizeAs: "timeseries",
+				GroupBy:     "service",
+				Columns:     []string{"host", "@http.url"},
+				FromTS:      "now-2h",
+				ToTS:        "now",
+			},
+			expected: &LinkDiff{
+				TimeWindow: &TimeWindowChange{
+					From:       FieldChange{Field: "fromTS", From: "now-1h", To: "now-2h"},
+					To:         FieldChange{Field: "toTS", From: "now", To: "now"},
+					StartShift: "-1h0m0s",
+					DurationA:  "1h0m0s",
+					DurationB:  "2h0m0s",
+				},
+				Changes: []FieldChange{
+					{Field: "columns", Added: []string{"@http.url"}, Removed: []string{"service"}},
+					{Field: "groupBy", To: "service"},
+					{Field: "query", Added: []string{"env:prod"}, Removed: []string{"service:web"}},
+					{Field: "viz", From: "stream", To: "timeseries"},
+				},
+			},
+		},
+		{
+			name: "absolute-time",
+			a:    &api.DatadogLink{FromTS: "1700000000000", ToTS: "1700003600000"},
+			b:    &api.DatadogLink{FromTS: "1700001800000", ToTS: "1700005400000"},
+			expected: &LinkDiff{
+				TimeWindow: &TimeWindowChange{
+					From:       FieldChange{Field: "fromTS", From: "1700000000000", To: "1700001800000"},
+					To:         FieldChange{Field: "toTS", From: "1700003600000", To: "1700005400000"},
+					StartShift: "+30m0s",
+					EndShift:   "+30m0s",
+					DurationA:  "1h0m0s",
+					DurationB:  "1h0m0s",
+				},
+			},
+		},
+		{
+			name: "nested-queries",
+			a: &api.DatadogLink{
+				Queries: []api.LogsQuery{{Measure: "@duration", GroupBy: []string{"service"}}},
+			},
+			b: &api.DatadogLink{
+				Queries: []api.LogsQuery{{Measure: "@latency", GroupBy: []string{"service", "env"}}, {AggType: "count"}},
+			},
+			expected: &LinkDiff{
+				Changes: []FieldChange{
+					{Field: "queries[0].groupBy", Added: []string{"env"}},
+					{Field: "queries[0].measure", From: "@duration", To: "@latency"},
+					{Field: "queries[1]", To: map[string]any{"aggType": "count"}},
+				},
+			},
+		},
+		{
+			name: "reordered",
+			a:    &api.DatadogLink{Columns: []string{"host", "service"}},
+			b:    &api.DatadogLink{Columns: []string{"service", "host"}},
+			expected: &LinkDiff{
+				Changes: []FieldChange{
+					{Field: "columns", Reordered: true},
+				},
+			},
+		},
+		{
+			name: "duplicates",
+			a:    &api.DatadogLink{Columns: []string{"host", "host", "service"}},
+			b:    &api.DatadogLink{Columns: []string{"service", "host"}},
+			expected: &LinkDiff{
+				Changes: []FieldChange{
+					{Field: "columns", Removed: []string{"host"}},
+				},
+			},
+		},
+		{
+			name: "kinds",
+			a:    &api.DatadogTrace{Kind: api.TraceGVK.Kind, TraceID: "abc"},
+			b:    &api.DatadogLink{Kind: api.LinkGVK.Kind, Query: "trace_id:abc"},
+			expected: &LinkDiff{
+				Changes: []FieldChange{
+					{Field: "kind", From: api.TraceGVK.Kind, To: api.LinkGVK.Kind},
+					{Field: "query", Added: []string{"trace_id:abc"}},
+					{Field: "traceID", From: "abc"},
+				},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			actual, err := DiffLinks(c.a, c.b)
+			if err != nil {
+				t.Fatalf("Failed to diff links: %+v", err)
+			}
+			if d := cmp.Diff(c.expected, actual); d != "" {
+				t.Errorf("Unexpected diff:\n%v", d)
+			}
+		})
+	}
+}
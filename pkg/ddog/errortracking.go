@@ -0,0 +1,62 @@
+package ddog
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/jlewi/ddctl/api"
+)
+
+const (
+	errorTrackingPath      = "/error-tracking"
+	errorTrackingIssuePath = "/error-tracking/issue/"
+)
+
+// BuildErrorTrackingURL builds the URL for an Error Tracking explorer or issue link.
+func BuildErrorTrackingURL(link *api.DatadogErrorTracking) (string, error) {
+	queryParams := url.Values{}
+
+	addString(queryParams, "query", link.Query)
+	addString(queryParams, "source", link.Source)
+	addString(queryParams, "sort", link.Sort)
+	addString(queryParams, "refresh_mode", link.RefreshMode)
+	if err := addTimeRange(queryParams, link.FromTS, link.ToTS); err != nil {
+		return "", err
+	}
+	addExtraParams(queryParams, link.ExtraParams)
+
+	p := errorTrackingPath
+	if link.IssueID != "" {
+		p = errorTrackingIssuePath + url.PathEscape(link.IssueID)
+	}
+
+	encodedQuery := queryParams.Encode()
+	u := fmt.Sprintf("%s%s?%s", link.BaseURL, p, encodedQuery)
+	return u, nil
+}
+
+// ErrorTrackingURLToLink converts an Error Tracking URL to a DatadogErrorTracking link.
+func ErrorTrackingURLToLink(u url.URL) (*api.DatadogErrorTracking, error) {
+	link := &api.DatadogErrorTracking{
+		APIVersion: api.ErrorTrackingGVK.GroupVersion().String(),
+		Kind:       api.ErrorTrackingGVK.Kind,
+		BaseURL:    getBaseURL(u),
+	}
+
+	queryParamMap := map[string]queryValHandler{
+		"query":        bindToString(&link.Query),
+		"source":       bindToString(&link.Source),
+		"sort":         bindToString(&link.Sort),
+		"refresh_mode": bindToString(&link.RefreshMode),
+		"from_ts":      bindToString(&link.FromTS),
+		"to_ts":        bindToString(&link.ToTS),
+	}
+
+	link.ExtraParams = bindQueryParams(u.Query(), queryParamMap)
+
+	if strings.HasPrefix(u.Path, errorTrackingIssuePath) {
+		link.IssueID = strings.Trim(strings.TrimPrefix(u.Path, errorTrackingIssuePath), "/")
+	}
+	return link, nil
+}
@@ -45,17 +45,49 @@ func relativeToAbsoluteTime(timeVal string) (string, error) {
 	return newTimestr, nil
 }
 
+// addTimeRange adds the from_ts and to_ts parameters converting any relative times to absolute times.
+func addTimeRange(values url.Values, fromTS string, toTS string) error {
+	from_ts, err := relativeToAbsoluteTime(fromTS)
+	if err != nil {
+		return errors.Wrapf(err, "Error converting from_ts relative to absolute time for %v", fromTS)
+	}
+	to_ts, err := relativeToAbsoluteTime(toTS)
+	if err != nil {
+		return errors.Wrapf(err, "Error converting to_ts relative to absolute time for %v", toTS)
+	}
+	addString(values, "from_ts", from_ts)
+	addString(values, "to_ts", to_ts)
+	return nil
+}
+
 func BuildURL(link *api.DatadogLink) (string, error) {
-	// Create a new url.Values object
-	queryParams := url.Values{}
+	switch link.Mode {
+	case "":
+	case api.LogsModeLiveTail:
+		return buildLiveTailURL(link)
+	default:
+		return "", errors.Errorf("Unsupported mode %v; mode must be empty or %v", link.Mode, api.LogsModeLiveTail)
+	}
 
-	from_ts, err := relativeToAbsoluteTime(link.FromTS)
+	queryParams, err := logsQueryParams(link)
 	if err != nil {
-		return "", errors.Wrapf(err, "Error converting from_ts relative to absolute time for %v", link.FromTS)
+		return "", err
 	}
-	to_ts, err := relativeToAbsoluteTime(link.ToTS)
-	if err != nil {
-		return "", errors.Wrapf(err, "Error converting to_ts relative to absolute time for %v", link.ToTS)
+
+	// Encode the values into a query string
+	encodedQuery := queryParams.Encode()
+	u := fmt.Sprintf("%s/logs?%s", link.BaseURL, encodedQuery)
+	return u, nil
+}
+
+// logsQueryParams returns the query parameters for the Log Explorer fields of link.
+// They are shared by the other pages that use the same analytics UI (e.g. Audit Trail).
+func logsQueryParams(link *api.DatadogLink) (url.Values, error) {
+	// Create a new url.Values object
+	queryParams := url.Values{}
+
+	if err := addTimeRange(queryParams, link.FromTS, link.ToTS); err != nil {
+		return nil, err
 	}
 	addString(queryParams, "query", link.Query)
 	addString(queryParams, "viz", link.VisualizeAs)
@@ -69,18 +101,16 @@ func BuildURL(link *api.DatadogLink) (string, error) {
 	addString(queryParams, "agg_q_source", link.GroupBySource)
 	addString(queryParams, "agg_t", link.AggType)
 	addString(queryParams, "refresh_mode", link.RefreshMode)
-	addString(queryParams, "from_ts", from_ts)
-	addString(queryParams, "to_ts", to_ts)
 	addString(queryParams, "fromUser", link.FromUser)
 	addString(queryParams, "top_n", strconv.Itoa(link.TopN))
 	addString(queryParams, "top_o", link.TopO)
 	addString(queryParams, "live", strconv.FormatBool(link.Live))
 	addString(queryParams, "cols", strings.Join(link.Columns, ","))
 	addString(queryParams, "messageDisplay", link.MessageDisplay)
-	// Encode the values into a query string
-	encodedQuery := queryParams.Encode()
-	u := fmt.Sprintf("%s/logs?%s", link.BaseURL, encodedQuery)
-	return u, nil
+	addString(queryParams, "saved-view-id", link.SavedView)
+	addString(queryParams, "index", strings.Join(link.Indexes, ","))
+	addIndexedQueries(queryParams, link.Queries, link.Formulas)
+	return queryParams, nil
 }
 
 func getBaseURL(parsedURL url.URL) string {
@@ -95,6 +125,18 @@ func getBaseURL(parsedURL url.URL) string {
 }
 
 func BuildTraceURL(link *api.DatadogTrace) (string, error) {
+	// Accept IDs in any format e.g. the hex IDs logged by OpenTelemetry; see NormalizeTraceIDs.
+	normalized := *link
+	format := TraceIDFormatAuto
+	if v, _ := trimHexPrefix(link.TraceID); len(v) == otelTraceIDLength {
+		// Keep IDs written as 128 bits in hex even if the upper bits are zero
+		format = TraceIDFormatHex
+	}
+	if err := NormalizeTraceIDs(&normalized, format); err != nil {
+		return "", err
+	}
+	link = &normalized
+
 	// Create a new url.Values object
 	queryParams := url.Values{}
 
@@ -158,15 +200,51 @@ func bindToStringSlice(field *[]string) queryValHandler {
 	}
 }
 
+// bindQueryParams invokes the handler for each query parameter that has one.
+// Parameters without a handler are returned so they can be stored in ExtraParams; nil is returned if there are none.
+func bindQueryParams(values url.Values, handlers map[string]queryValHandler) map[string]string {
+	extra := map[string]string{}
+	for key, value := range values {
+		if targetFunc, found := handlers[key]; found {
+			targetFunc(value)
+		} else {
+			extra[key] = value[0]
+		}
+	}
+
+	if len(extra) == 0 {
+		return nil
+	}
+	return extra
+}
+
+// addExtraParams adds the extra parameters to values.
+func addExtraParams(values url.Values, extra map[string]string) {
+	for key, value := range extra {
+		addString(values, key, value)
+	}
+}
+
 func LogsURLToLink(u url.URL) (*api.DatadogLink, error) {
 	link := &api.DatadogLink{
-		APIVersion:  api.LinkGVK.GroupVersion().String(),
-		Kind:        api.LinkGVK.Kind,
-		BaseURL:     getBaseURL(u),
-		ExtraParams: map[string]string{},
+		APIVersion: api.LinkGVK.GroupVersion().String(),
+		Kind:       api.LinkGVK.Kind,
+		BaseURL:    getBaseURL(u),
 	}
 
-	queryParamMap := map[string]queryValHandler{
+	if strings.HasPrefix(u.Path, liveTailPath) {
+		link.Mode = api.LogsModeLiveTail
+	}
+
+	values := u.Query()
+	link.Queries, link.Formulas = bindIndexedQueries(values)
+	link.ExtraParams = bindQueryParams(values, logsQueryParamMap(link))
+	return link, nil
+}
+
+// logsQueryParamMap returns the handlers binding the Log Explorer query parameters to the fields of link.
+func logsQueryParamMap(link *api.DatadogLink) map[string]queryValHandler {
+	return map[string]queryValHandler{
 		"query":                         bindToString(&link.Query),
 		"viz":                           bindToString(&link.VisualizeAs),
 		"agg_m":                         bindToString(&link.GroupInto),
@@ -188,28 +266,16 @@ func LogsURLToLink(u url.URL) (*api.DatadogLink, error) {
 		"live":                          bindToBool(&link.Live),
 		"cols":                          bindToStringSlice(&link.Columns),
 		"messageDisplay":                bindToString(&link.MessageDisplay),
+		"saved-view-id":                 bindToString(&link.SavedView),
+		"index":                         bindToStringSlice(&link.Indexes),
 	}
-
-	for key, value := range u.Query() {
-		if targetFunc, found := queryParamMap[key]; found {
-			targetFunc(value)
-		} else {
-			link.ExtraParams[key] = value[0]
-		}
-	}
-
-	if len(link.ExtraParams) == 0 {
-		link.ExtraParams = nil
-	}
-	return link, nil
 }
 
 func TraceURLToLink(u url.URL) (*api.DatadogTrace, error) {
 	link := &api.DatadogTrace{
-		APIVersion:  api.TraceGVK.GroupVersion().String(),
-		Kind:        api.TraceGVK.Kind,
-		BaseURL:     getBaseURL(u),
-		ExtraParams: map[string]string{},
+		APIVersion: api.TraceGVK.GroupVersion().String(),
+		Kind:       api.TraceGVK.Kind,
+		BaseURL:    getBaseURL(u),
 	}
 
 	queryParamMap := map[string]queryValHandler{
@@ -221,27 +287,17 @@ func TraceURLToLink(u url.URL) (*api.DatadogTrace, error) {
 		"shouldShowLegend": bindToBool(&link.ShouldShowLegend),
 	}
 
-	for key, value := range u.Query() {
-		if targetFunc, found := queryParamMap[key]; found {
-			targetFunc(value)
-		} else {
-			link.ExtraParams[key] = value[0]
-		}
-	}
+	link.ExtraParams = bindQueryParams(u.Query(), queryParamMap)
 
 	// TraceID is the final part of the link
 	parts := strings.Split(u.Path, "/")
 	if len(parts) > 0 {
 		link.TraceID = parts[len(parts)-1]
 	}
-
-	if len(link.ExtraParams) == 0 {
-		link.ExtraParams = nil
-	}
 	return link, nil
 }
 
-// URLToLink converts a URL to a DatadogLink or DatadogTrace
+// URLToLink converts a URL to the link resource for the page it points to (e.g. DatadogLink, DatadogTrace)
 func URLToLink(inputURL string) (any, error) {
 	parsedURL, err := url.Parse(inputURL)
 	if err != nil {
@@ -252,9 +308,82 @@ func URLToLink(inputURL string) (any, error) {
 		return LogsURLToLink(*parsedURL)
 	}
 
+	// N.B. This must be checked before /apm/trace which is a prefix of it
+	if strings.HasPrefix(parsedURL.Path, traceSearchPath) {
+		return TraceSearchURLToLink(*parsedURL)
+	}
+
 	if strings.HasPrefix(parsedURL.Path, "/apm/trace") {
 		return TraceURLToLink(*parsedURL)
 	}
 
+	if strings.HasPrefix(parsedURL.Path, errorTrackingPath) {
+		return ErrorTrackingURLToLink(*parsedURL)
+	}
+
+	if strings.HasPrefix(parsedURL.Path, databasesPath) {
+		return DatabaseURLToLink(*parsedURL)
+	}
+
+	if strings.HasPrefix(parsedURL.Path, ciPath+"/") {
+		return CIURLToLink(*parsedURL)
+	}
+
+	if strings.HasPrefix(parsedURL.Path, securitySignalsPath) {
+		return SecuritySignalURLToLink(*parsedURL)
+	}
+
+	if strings.HasPrefix(parsedURL.Path, llmTracesPath) {
+		return LLMTraceURLToLink(*parsedURL)
+	}
+
+	if strings.HasPrefix(parsedURL.Path, functionsPath) {
+		return ServerlessURLToLink(*parsedURL)
+	}
+
+	if strings.HasPrefix(parsedURL.Path, networkPath) {
+		return NetworkURLToLink(*parsedURL)
+	}
+
+	if strings.HasPrefix(parsedURL.Path, auditTrailPath) {
+		return AuditURLToLink(*parsedURL)
+	}
+
+	if strings.HasPrefix(parsedURL.Path, servicesPath) {
+		return CatalogEntityURLToLink(*parsedURL)
+	}
+
 	return nil, errors.Errorf("unsupported path: %v", parsedURL.Path)
 }
+
+// LinkToURL builds the URL for any of the link resources returned by URLToLink.
+func LinkToURL(link any) (string, error) {
+	switch v := link.(type) {
+	case *api.DatadogLink:
+		return BuildURL(v)
+	case *api.DatadogTrace:
+		return BuildTraceURL(v)
+	case *api.DatadogErrorTracking:
+		return BuildErrorTrackingURL(v)
+	case *api.DatadogDatabase:
+		return BuildDatabaseURL(v)
+	case *api.DatadogCI:
+		return BuildCIURL(v)
+	case *api.DatadogSecuritySignal:
+		return BuildSecuritySignalURL(v)
+	case *api.DatadogLLMTrace:
+		return BuildLLMTraceURL(v)
+	case *api.DatadogServerless:
+		return BuildServerlessURL(v)
+	case *api.DatadogNetwork:
+		return BuildNetworkURL(v)
+	case *api.DatadogAudit:
+		return BuildAuditURL(v)
+	case *api.DatadogCatalogEntity:
+		return BuildCatalogEntityURL(v)
+	case *api.DatadogTraceSearch:
+		return BuildTraceSearchURL(v)
+	default:
+		return "", errors.Errorf("Unsupported link type %T", link)
+	}
+}
@@ -32,6 +32,132 @@ func TestBuildURL(t *testing.T) {
 			Input:       &api.DatadogTrace{},
 			ExpectedURL: "https://acme.datadoghq.com/apm/trace/97db769b5b0c62ac69127dc786026bc7?graphType=waterfall&panel_tab=flamegraph&shouldShowLegend=true&sort=time&spanID=2754376459340700567&timeHint=1737673742952",
 		},
+		{
+			Name:        "error-tracking",
+			InputFile:   "errortracking.yaml",
+			Input:       &api.DatadogErrorTracking{},
+			ExpectedURL: "https://acme.datadoghq.com/error-tracking?query=service%3Afeserver%20env%3Aprod&source=backend&sort=TOTAL_COUNT&refresh_mode=paused&from_ts=1736927929003&to_ts=1736949529003",
+		},
+		{
+			Name:        "error-tracking-issue",
+			InputFile:   "errortracking_issue.yaml",
+			Input:       &api.DatadogErrorTracking{},
+			ExpectedURL: "https://acme.datadoghq.com/error-tracking/issue/4f5c3b6a-0b6e-11ef-9f0a-da7ad0900002?source=logs&from_ts=1736927929003&to_ts=1736949529003",
+		},
+		{
+			Name:        "database-queries",
+			InputFile:   "database_queries.yaml",
+			Input:       &api.DatadogDatabase{},
+			ExpectedURL: "https://acme.datadoghq.com/databases/queries?query=env%3Aprod%20service%3Aorders-db&dbms=postgres&sort=-total_duration&from_ts=1736927929003&to_ts=1736949529003",
+		},
+		{
+			Name:        "database-samples",
+			InputFile:   "database_samples.yaml",
+			Input:       &api.DatadogDatabase{},
+			ExpectedURL: "https://acme.datadoghq.com/databases/samples?query=env%3Aprod&query_signature=8fd2b3d1e6a7f5c4&host=orders-db-1&from_ts=1736927929003&to_ts=1736949529003",
+		},
+		{
+			Name:        "ci-test-runs",
+			InputFile:   "ci_test_runs.yaml",
+			Input:       &api.DatadogCI{},
+			ExpectedURL: "https://acme.datadoghq.com/ci/test-runs?query=%40git.repository.id%3Agithub.com%2Fjlewi%2Fddctl%20%40git.branch%3Amain%20%40git.commit.sha%3A7bf6161e0c4f%20%40test.status%3Afail&from_ts=1736927929003&to_ts=1736949529003",
+		},
+		{
+			Name:        "ci-pipelines",
+			InputFile:   "ci_pipelines.yaml",
+			Input:       &api.DatadogCI{},
+			ExpectedURL: "https://acme.datadoghq.com/ci/pipeline-executions?query=%40git.branch%3Amain%20%40ci.pipeline.name%3A%22build%20and%20test%22&from_ts=1736927929003&to_ts=1736949529003",
+		},
+		{
+			Name:        "security-signals",
+			InputFile:   "security_signals.yaml",
+			Input:       &api.DatadogSecuritySignal{},
+			ExpectedURL: "https://acme.datadoghq.com/security/signals?query=status%3Ahigh%20%40workflow.triage.state%3Aopen%20%40workflow.rule.id%3Adef-000-abc%20env%3Aprod&from_ts=1736927929003&to_ts=1736949529003",
+		},
+		{
+			Name:        "security-signal",
+			InputFile:   "security_signal.yaml",
+			Input:       &api.DatadogSecuritySignal{},
+			ExpectedURL: "https://acme.datadoghq.com/security/signals?event=AQAAAYvTsyC3OqpNIQAAAABBWXZUc3lDM0FBQ0FfZXh0cg&from_ts=1736927929003&to_ts=1736949529003",
+		},
+		{
+			Name:        "live-tail",
+			InputFile:   "livetail.yaml",
+			Input:       &api.DatadogLink{},
+			ExpectedURL: "https://acme.datadoghq.com/logs/livetail?query=service%3Afeserver%20status%3Aerror&cols=host%2Cservice&messageDisplay=inline",
+		},
+		{
+			Name:        "saved-view",
+			InputFile:   "saved_view.yaml",
+			Input:       &api.DatadogLink{},
+			ExpectedURL: "https://acme.datadoghq.com/logs?query=service%3Afeserver&saved-view-id=1234&index=main%2Cflex&from_ts=1736927929003&to_ts=1736949529003&live=false&viz=stream&top_n=0",
+		},
+		{
+			Name:        "llm-traces",
+			InputFile:   "llm_traces.yaml",
+			Input:       &api.DatadogLLMTrace{},
+			ExpectedURL: "https://acme.datadoghq.com/llm/traces?query=%40ml_app%3Afoyle%20%40meta.span.kind%3Aagent%20%40status%3Aerror&from_ts=1736927929003&to_ts=1736949529003",
+		},
+		{
+			Name:        "llm-trace",
+			InputFile:   "llm_trace.yaml",
+			Input:       &api.DatadogLLMTrace{},
+			ExpectedURL: "https://acme.datadoghq.com/llm/traces?query=%40ml_app%3Afoyle&traceId=66e7d6a500000000a1b2c3d4e5f60718&from_ts=1736927929003&to_ts=1736949529003",
+		},
+		{
+			Name:        "serverless-list",
+			InputFile:   "serverless_list.yaml",
+			Input:       &api.DatadogServerless{},
+			ExpectedURL: "https://acme.datadoghq.com/functions?query=region%3Aus-east-1%20env%3Aprod%20functionname%3Acheckout%2A&from_ts=1736927929003&to_ts=1736949529003",
+		},
+		{
+			Name:        "serverless-arn",
+			InputFile:   "serverless_arn.yaml",
+			Input:       &api.DatadogServerless{},
+			ExpectedURL: "https://acme.datadoghq.com/functions/arn:aws:lambda:us-east-1:123456789012:function:checkout?query=env%3Aprod%20version%3A42%20%40status%3Aerror&tab=invocations&from_ts=1736927929003&to_ts=1736949529003",
+		},
+		{
+			Name:        "network-analytics",
+			InputFile:   "network_analytics.yaml",
+			Input:       &api.DatadogNetwork{},
+			ExpectedURL: "https://acme.datadoghq.com/network/analytics?groupby_source=service&groupby_dest=service%2Cavailability-zone&source_query=service%3Afeserver%20env%3Aprod&dest_query=service%3Aorders&metric=tcp_retransmits&from_ts=1736927929003&to_ts=1736949529003",
+		},
+		{
+			Name:        "network-map",
+			InputFile:   "network_map.yaml",
+			Input:       &api.DatadogNetwork{},
+			ExpectedURL: "https://acme.datadoghq.com/network/map?groupby_source=service&metric=volume_sent&from_ts=1736927929003&to_ts=1736949529003",
+		},
+		{
+			Name:        "audit-trail",
+			InputFile:   "audit_trail.yaml",
+			Input:       &api.DatadogAudit{},
+			ExpectedURL: "https://acme.datadoghq.com/audit-trail?query=%40evt.name%3AMonitor%20%40asset.id%3A1234%20%40action%3Amodified&cols=%40usr.email%2C%40action&viz=stream&stream_sort=desc&top_n=0&live=false&from_ts=1736927929003&to_ts=1736949529003",
+		},
+		{
+			Name:        "catalog-entity",
+			InputFile:   "catalog_entity.yaml",
+			Input:       &api.DatadogCatalogEntity{},
+			ExpectedURL: "https://acme.datadoghq.com/services/feserver?tab=ownership&env=prod",
+		},
+		{
+			Name:        "catalog-entity-scorecards",
+			InputFile:   "catalog_entity_scorecards.yaml",
+			Input:       &api.DatadogCatalogEntity{},
+			ExpectedURL: "https://acme.datadoghq.com/services/orders-api?tab=scorecards",
+		},
+		{
+			Name:        "multi-query",
+			InputFile:   "multi_query.yaml",
+			Input:       &api.DatadogLink{},
+			ExpectedURL: "https://acme.datadoghq.com/logs?query=service%3Afeserver&viz=timeseries&agg_m_0=count&agg_m_source_0=base&agg_t_0=count&agg_q_0=status%2C%40http.method&agg_q_source_0=base&top_n_0=10&top_o_0=top&agg_m_1=%40duration&agg_m_source_1=base&agg_t_1=pc99&agg_q_1=service&top_n_1=0&formula_0=a%20%2F%20b&formula_alias_0=errors%20per%20request&top_n=0&live=false&from_ts=1736927929003&to_ts=1736949529003",
+		},
+		{
+			Name:        "trace-search",
+			InputFile:   "trace_search.yaml",
+			Input:       &api.DatadogTraceSearch{},
+			ExpectedURL: "https://acme.datadoghq.com/apm/traces?agg_m=%40duration&agg_q=service%2Cresource_name&agg_t=pc99&from_ts=1736927929003&query=env%3Aprod+%40http.status_code%3A%3E%3D500&to_ts=1736949529003&top_n=10&viz=toplist",
+		},
 	}
 	cwd, err := os.Getwd()
 	if err != nil {
@@ -58,6 +184,26 @@ func TestBuildURL(t *testing.T) {
 				resultURL, buildErr = BuildURL(v)
 			case *api.DatadogTrace:
 				resultURL, buildErr = BuildTraceURL(v)
+			case *api.DatadogErrorTracking:
+				resultURL, buildErr = BuildErrorTrackingURL(v)
+			case *api.DatadogDatabase:
+				resultURL, buildErr = BuildDatabaseURL(v)
+			case *api.DatadogCI:
+				resultURL, buildErr = BuildCIURL(v)
+			case *api.DatadogSecuritySignal:
+				resultURL, buildErr = BuildSecuritySignalURL(v)
+			case *api.DatadogLLMTrace:
+				resultURL, buildErr = BuildLLMTraceURL(v)
+			case *api.DatadogServerless:
+				resultURL, buildErr = BuildServerlessURL(v)
+			case *api.DatadogNetwork:
+				resultURL, buildErr = BuildNetworkURL(v)
+			case *api.DatadogAudit:
+				resultURL, buildErr = BuildAuditURL(v)
+			case *api.DatadogCatalogEntity:
+				resultURL, buildErr = BuildCatalogEntityURL(v)
+			case *api.DatadogTraceSearch:
+				resultURL, buildErr = BuildTraceSearchURL(v)
 			}
 
 			if buildErr != nil {
@@ -115,6 +261,132 @@ func TestParseURL(t *testing.T) {
 			Expected:     &api.DatadogTrace{},
 			ExpectedFile: "trace.yaml",
 		},
+		{
+			Name:         "error-tracking",
+			Input:        "https://acme.datadoghq.com/error-tracking?query=service%3Afeserver%20env%3Aprod&source=backend&sort=TOTAL_COUNT&refresh_mode=paused&from_ts=1736927929003&to_ts=1736949529003",
+			Expected:     &api.DatadogErrorTracking{},
+			ExpectedFile: "errortracking.yaml",
+		},
+		{
+			Name:         "error-tracking-issue",
+			Input:        "https://acme.datadoghq.com/error-tracking/issue/4f5c3b6a-0b6e-11ef-9f0a-da7ad0900002?source=logs&from_ts=1736927929003&to_ts=1736949529003",
+			Expected:     &api.DatadogErrorTracking{},
+			ExpectedFile: "errortracking_issue.yaml",
+		},
+		{
+			Name:         "database-queries",
+			Input:        "https://acme.datadoghq.com/databases/queries?query=env%3Aprod%20service%3Aorders-db&dbms=postgres&sort=-total_duration&from_ts=1736927929003&to_ts=1736949529003",
+			Expected:     &api.DatadogDatabase{},
+			ExpectedFile: "database_queries.yaml",
+		},
+		{
+			Name:         "database-samples",
+			Input:        "https://acme.datadoghq.com/databases/samples?query=env%3Aprod&query_signature=8fd2b3d1e6a7f5c4&host=orders-db-1&from_ts=1736927929003&to_ts=1736949529003",
+			Expected:     &api.DatadogDatabase{},
+			ExpectedFile: "database_samples.yaml",
+		},
+		{
+			Name:         "ci-test-runs",
+			Input:        "https://acme.datadoghq.com/ci/test-runs?query=%40git.repository.id%3Agithub.com%2Fjlewi%2Fddctl%20%40git.branch%3Amain%20%40git.commit.sha%3A7bf6161e0c4f%20%40test.status%3Afail&from_ts=1736927929003&to_ts=1736949529003",
+			Expected:     &api.DatadogCI{},
+			ExpectedFile: "ci_test_runs.yaml",
+		},
+		{
+			Name:         "ci-pipelines",
+			Input:        "https://acme.datadoghq.com/ci/pipeline-executions?query=%40git.branch%3Amain%20%40ci.pipeline.name%3A%22build%20and%20test%22&from_ts=1736927929003&to_ts=1736949529003",
+			Expected:     &api.DatadogCI{},
+			ExpectedFile: "ci_pipelines.yaml",
+		},
+		{
+			Name:         "security-signals",
+			Input:        "https://acme.datadoghq.com/security/signals?query=status%3Ahigh%20%40workflow.triage.state%3Aopen%20%40workflow.rule.id%3Adef-000-abc%20env%3Aprod&from_ts=1736927929003&to_ts=1736949529003",
+			Expected:     &api.DatadogSecuritySignal{},
+			ExpectedFile: "security_signals.yaml",
+		},
+		{
+			Name:         "security-signal",
+			Input:        "https://acme.datadoghq.com/security/signals?event=AQAAAYvTsyC3OqpNIQAAAABBWXZUc3lDM0FBQ0FfZXh0cg&from_ts=1736927929003&to_ts=1736949529003",
+			Expected:     &api.DatadogSecuritySignal{},
+			ExpectedFile: "security_signal.yaml",
+		},
+		{
+			Name:         "live-tail",
+			Input:        "https://acme.datadoghq.com/logs/livetail?query=service%3Afeserver%20status%3Aerror&cols=host%2Cservice&messageDisplay=inline",
+			Expected:     &api.DatadogLink{},
+			ExpectedFile: "livetail.yaml",
+		},
+		{
+			Name:         "saved-view",
+			Input:        "https://acme.datadoghq.com/logs?query=service%3Afeserver&saved-view-id=1234&index=main%2Cflex&from_ts=1736927929003&to_ts=1736949529003&live=false&viz=stream&top_n=0",
+			Expected:     &api.DatadogLink{},
+			ExpectedFile: "saved_view.yaml",
+		},
+		{
+			Name:         "llm-traces",
+			Input:        "https://acme.datadoghq.com/llm/traces?query=%40ml_app%3Afoyle%20%40meta.span.kind%3Aagent%20%40status%3Aerror&from_ts=1736927929003&to_ts=1736949529003",
+			Expected:     &api.DatadogLLMTrace{},
+			ExpectedFile: "llm_traces.yaml",
+		},
+		{
+			Name:         "llm-trace",
+			Input:        "https://acme.datadoghq.com/llm/traces?query=%40ml_app%3Afoyle&traceId=66e7d6a500000000a1b2c3d4e5f60718&from_ts=1736927929003&to_ts=1736949529003",
+			Expected:     &api.DatadogLLMTrace{},
+			ExpectedFile: "llm_trace.yaml",
+		},
+		{
+			Name:         "serverless-list",
+			Input:        "https://acme.datadoghq.com/functions?query=region%3Aus-east-1%20env%3Aprod%20functionname%3Acheckout%2A&from_ts=1736927929003&to_ts=1736949529003",
+			Expected:     &api.DatadogServerless{},
+			ExpectedFile: "serverless_list.yaml",
+		},
+		{
+			Name:         "serverless-arn",
+			Input:        "https://acme.datadoghq.com/functions/arn:aws:lambda:us-east-1:123456789012:function:checkout?query=env%3Aprod%20version%3A42%20%40status%3Aerror&tab=invocations&from_ts=1736927929003&to_ts=1736949529003",
+			Expected:     &api.DatadogServerless{},
+			ExpectedFile: "serverless_arn.yaml",
+		},
+		{
+			Name:         "network-analytics",
+			Input:        "https://acme.datadoghq.com/network/analytics?groupby_source=service&groupby_dest=service%2Cavailability-zone&source_query=service%3Afeserver%20env%3Aprod&dest_query=service%3Aorders&metric=tcp_retransmits&from_ts=1736927929003&to_ts=1736949529003",
+			Expected:     &api.DatadogNetwork{},
+			ExpectedFile: "network_analytics.yaml",
+		},
+		{
+			Name:         "network-map",
+			Input:        "https://acme.datadoghq.com/network/map?groupby_source=service&metric=volume_sent&from_ts=1736927929003&to_ts=1736949529003",
+			Expected:     &api.DatadogNetwork{},
+			ExpectedFile: "network_map.yaml",
+		},
+		{
+			Name:         "audit-trail",
+			Input:        "https://acme.datadoghq.com/audit-trail?query=%40evt.name%3AMonitor%20%40asset.id%3A1234%20%40action%3Amodified&cols=%40usr.email%2C%40action&viz=stream&stream_sort=desc&top_n=0&live=false&from_ts=1736927929003&to_ts=1736949529003",
+			Expected:     &api.DatadogAudit{},
+			ExpectedFile: "audit_trail.yaml",
+		},
+		{
+			Name:         "catalog-entity",
+			Input:        "https://acme.datadoghq.com/services/feserver?tab=ownership&env=prod",
+			Expected:     &api.DatadogCatalogEntity{},
+			ExpectedFile: "catalog_entity.yaml",
+		},
+		{
+			Name:         "catalog-entity-scorecards",
+			Input:        "https://acme.datadoghq.com/services/orders-api?tab=scorecards",
+			Expected:     &api.DatadogCatalogEntity{},
+			ExpectedFile: "catalog_entity_scorecards.yaml",
+		},
+		{
+			Name:         "multi-query",
+			Input:        "https://acme.datadoghq.com/logs?query=service%3Afeserver&viz=timeseries&agg_m_0=count&agg_m_source_0=base&agg_t_0=count&agg_q_0=status%2C%40http.method&agg_q_source_0=base&top_n_0=10&top_o_0=top&agg_m_1=%40duration&agg_m_source_1=base&agg_t_1=pc99&agg_q_1=service&top_n_1=0&formula_0=a%20%2F%20b&formula_alias_0=errors%20per%20request&top_n=0&live=false&from_ts=1736927929003&to_ts=1736949529003",
+			Expected:     &api.DatadogLink{},
+			ExpectedFile: "multi_query.yaml",
+		},
+		{
+			Name:         "trace-search",
+			Input:        "https://acme.datadoghq.com/apm/traces?agg_m=%40duration&agg_q=service%2Cresource_name&agg_t=pc99&from_ts=1736927929003&query=env%3Aprod+%40http.status_code%3A%3E%3D500&to_ts=1736949529003&top_n=10&viz=toplist",
+			Expected:     &api.DatadogTraceSearch{},
+			ExpectedFile: "trace_search.yaml",
+		},
 	}
 	cwd, err := os.Getwd()
 	if err != nil {
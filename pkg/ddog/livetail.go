@@ -0,0 +1,74 @@
+package ddog
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/jlewi/ddctl/api"
+	"github.com/pkg/errors"
+)
+
+const (
+	liveTailPath = "/logs/livetail"
+)
+
+// ValidateLiveTail returns an error if the link sets fields that don't make sense for Live Tail.
+// Live Tail streams logs as they arrive so it has no time window and doesn't support analytics.
+func ValidateLiveTail(link *api.DatadogLink) error {
+	fields := map[string]bool{
+		"fromTS":                     link.FromTS != "",
+		"toTS":                       link.ToTS != "",
+		"live":                       link.Live,
+		"refreshMode":                link.RefreshMode != "",
+		"viz":                        link.VisualizeAs != "" && link.VisualizeAs != "stream",
+		"groupBy":                    link.GroupBy != "",
+		"groupBySource":              link.GroupBySource != "",
+		"groupInto":                  link.GroupInto != "",
+		"source":                     link.Source != "",
+		"aggType":                    link.AggType != "",
+		"topN":                       link.TopN != 0,
+		"topO":                       link.TopO != "",
+		"clusteringPatternFieldPath": link.ClusteringPatternFieldPath != "",
+		"missing":                    link.Missing != "",
+		"streamSort":                 link.StreamSort != "",
+		"queries":                    len(link.Queries) > 0,
+		"formulas":                   len(link.Formulas) > 0,
+	}
+
+	invalid := make([]string, 0, len(fields))
+	for name, isSet := range fields {
+		if isSet {
+			invalid = append(invalid, name)
+		}
+	}
+
+	if len(invalid) == 0 {
+		return nil
+	}
+	// Sort so the error message is deterministic
+	slices.Sort(invalid)
+	return errors.Errorf("Live Tail links don't support a time window or analytics; unset %v or change mode", strings.Join(invalid, ", "))
+}
+
+// buildLiveTailURL builds the URL for a DatadogLink in Live Tail mode.
+func buildLiveTailURL(link *api.DatadogLink) (string, error) {
+	if err := ValidateLiveTail(link); err != nil {
+		return "", err
+	}
+
+	queryParams := url.Values{}
+	addString(queryParams, "query", link.Query)
+	addString(queryParams, "storage", link.Storage)
+	addString(queryParams, "cols", strings.Join(link.Columns, ","))
+	addString(queryParams, "messageDisplay", link.MessageDisplay)
+	addString(queryParams, "fromUser", link.FromUser)
+	addString(queryParams, "saved-view-id", link.SavedView)
+	addString(queryParams, "index", strings.Join(link.Indexes, ","))
+	addExtraParams(queryParams, link.ExtraParams)
+
+	encodedQuery := queryParams.Encode()
+	u := fmt.Sprintf("%s%s?%s", link.BaseURL, liveTailPath, encodedQuery)
+	return u, nil
+}
@@ -0,0 +1,70 @@
+package ddog
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jlewi/ddctl/api"
+)
+
+func TestBuildURL_LiveTailInvalid(t *testing.T) {
+	type testCase struct {
+		Name          string
+		Link          *api.DatadogLink
+		ExpectedError string
+	}
+
+	cases := []testCase{
+		{
+			Name: "fixed-window",
+			Link: &api.DatadogLink{
+				Mode:   api.LogsModeLiveTail,
+				Query:  "service:feserver",
+				FromTS: "1736927929003",
+				ToTS:   "1736949529003",
+			},
+			ExpectedError: "unset fromTS, toTS",
+		},
+		{
+			Name: "analytics",
+			Link: &api.DatadogLink{
+				Mode:        api.LogsModeLiveTail,
+				VisualizeAs: "timeseries",
+				GroupBy:     "status",
+			},
+			ExpectedError: "unset groupBy, viz",
+		},
+		{
+			Name: "multi-query",
+			Link: &api.DatadogLink{
+				Mode: api.LogsModeLiveTail,
+				Queries: []api.LogsQuery{
+					{Measure: "count", AggType: "count"},
+					{Measure: "count", AggType: "count"},
+				},
+				Formulas: []api.LogsFormula{{Formula: "a / b"}},
+				Missing:  "include",
+			},
+			ExpectedError: "unset formulas, missing, queries",
+		},
+		{
+			Name: "unknown-mode",
+			Link: &api.DatadogLink{
+				Mode: "tail",
+			},
+			ExpectedError: "Unsupported mode tail",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			_, err := BuildURL(c.Link)
+			if err == nil {
+				t.Fatalf("Expected an error")
+			}
+			if !strings.Contains(err.Error(), c.ExpectedError) {
+				t.Errorf("Error %v doesn't contain %v", err.Error(), c.ExpectedError)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package ddog
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/jlewi/ddctl/api"
+)
+
+const (
+	llmTracesPath = "/llm/traces"
+)
+
+// llmTraceFacets returns the query facets for the structured fields of the link.
+func llmTraceFacets(link *api.DatadogLLMTrace) []facetField {
+	return []facetField{
+		{key: "@ml_app", field: &link.MLApp},
+		{key: "@meta.span.kind", field: &link.SpanKind},
+	}
+}
+
+// BuildLLMTraceURL builds the URL for an LLM Observability traces link.
+func BuildLLMTraceURL(link *api.DatadogLLMTrace) (string, error) {
+	queryParams := url.Values{}
+	addString(queryParams, "query", buildFacetQuery(llmTraceFacets(link), link.Query))
+	addString(queryParams, "traceId", link.TraceID)
+	if err := addTimeRange(queryParams, link.FromTS, link.ToTS); err != nil {
+		return "", err
+	}
+	addExtraParams(queryParams, link.ExtraParams)
+
+	encodedQuery := queryParams.Encode()
+	u := fmt.Sprintf("%s%s?%s", link.BaseURL, llmTracesPath, encodedQuery)
+	return u, nil
+}
+
+// LLMTraceURLToLink converts an LLM Observability traces URL to a DatadogLLMTrace link.
+func LLMTraceURLToLink(u url.URL) (*api.DatadogLLMTrace, error) {
+	link := &api.DatadogLLMTrace{
+		APIVersion: api.LLMTraceGVK.GroupVersion().String(),
+		Kind:       api.LLMTraceGVK.Kind,
+		BaseURL:    getBaseURL(u),
+	}
+
+	queryParamMap := map[string]queryValHandler{
+		"query":   bindToString(&link.Query),
+		"traceId": bindToString(&link.TraceID),
+		"from_ts": bindToString(&link.FromTS),
+		"to_ts":   bindToString(&link.ToTS),
+	}
+
+	link.ExtraParams = bindQueryParams(u.Query(), queryParamMap)
+	link.Query = extractFacets(llmTraceFacets(link), link.Query)
+	return link, nil
+}
@@ -0,0 +1,110 @@
+package ddog
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/zapr"
+	"github.com/jlewi/ddctl/api"
+	"go.uber.org/zap"
+)
+
+var (
+	// indexedParamRegex matches the query keys of multi-query views e.g. agg_m_0 or formula_alias_1.
+	// Longer prefixes come first so agg_m_source_0 isn't matched as agg_m.
+	indexedParamRegex = regexp.MustCompile(`^(agg_m_source|agg_m|agg_t|agg_q_source|agg_q|top_n|top_o|x_missing|formula_alias|formula)_(\d+)$`)
+)
+
+const (
+	// maxIndexedQueries is the maximum number of queries or formulas in a multi-query view. Datadog names the
+	// queries a through z. Keys with larger indexes are left in ExtraParams.
+	maxIndexedQueries = 26
+)
+
+// addIndexedQueries adds the query keys for the queries and formulas of a multi-query view.
+func addIndexedQueries(values url.Values, queries []api.LogsQuery, formulas []api.LogsFormula) {
+	for i, q := range queries {
+		suffix := "_" + strconv.Itoa(i)
+		addString(values, "agg_m"+suffix, q.Measure)
+		addString(values, "agg_m_source"+suffix, q.MeasureSource)
+		addString(values, "agg_t"+suffix, q.AggType)
+		addString(values, "agg_q"+suffix, strings.Join(q.GroupBy, ","))
+		addString(values, "agg_q_source"+suffix, q.GroupBySource)
+		if q.TopN != nil {
+			addString(values, "top_n"+suffix, strconv.Itoa(*q.TopN))
+		}
+		addString(values, "top_o"+suffix, q.TopO)
+		addString(values, "x_missing"+suffix, q.Missing)
+	}
+
+	for i, f := range formulas {
+		suffix := "_" + strconv.Itoa(i)
+		addString(values, "formula"+suffix, f.Formula)
+		addString(values, "formula_alias"+suffix, f.Alias)
+	}
+}
+
+// bindIndexedQueries parses the query keys of a multi-query view into queries and formulas.
+// The keys are removed from values so the remaining keys can be bound to the other fields.
+func bindIndexedQueries(values url.Values) ([]api.LogsQuery, []api.LogsFormula) {
+	log := zapr.NewLogger(zap.L())
+	var queries []api.LogsQuery
+	var formulas []api.LogsFormula
+
+	for key, value := range values {
+		m := indexedParamRegex.FindStringSubmatch(key)
+		if m == nil || len(value) == 0 {
+			continue
+		}
+		index, err := strconv.Atoi(m[2])
+		if err != nil || index >= maxIndexedQueries {
+			log.Info("Ignoring query key with an index out of range", "key", key, "max", maxIndexedQueries-1)
+			continue
+		}
+		delete(values, key)
+
+		field := m[1]
+		v := value[0]
+		if strings.HasPrefix(field, "formula") {
+			for len(formulas) <= index {
+				formulas = append(formulas, api.LogsFormula{})
+			}
+			f := &formulas[index]
+			switch field {
+			case "formula":
+				f.Formula = v
+			case "formula_alias":
+				f.Alias = v
+			}
+			continue
+		}
+
+		for len(queries) <= index {
+			queries = append(queries, api.LogsQuery{})
+		}
+		q := &queries[index]
+		switch field {
+		case "agg_m":
+			q.Measure = v
+		case "agg_m_source":
+			q.MeasureSource = v
+		case "agg_t":
+			q.AggType = v
+		case "agg_q":
+			q.GroupBy = strings.Split(v, ",")
+		case "agg_q_source":
+			q.GroupBySource = v
+		case "top_n":
+			topN := 0
+			bindToInt(&topN)(value)
+			q.TopN = &topN
+		case "top_o":
+			q.TopO = v
+		case "x_missing":
+			q.Missing = v
+		}
+	}
+	return queries, formulas
+}
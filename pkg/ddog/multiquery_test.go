@@ -0,0 +1,38 @@
+package ddog
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jlewi/ddctl/api"
+)
+
+func TestBindIndexedQueries_OutOfRange(t *testing.T) {
+	values := url.Values{
+		"agg_m_0":          []string{"count"},
+		"top_n_0":          []string{"0"},
+		"agg_m_1000000000": []string{"count"},
+		"formula_26":       []string{"a / b"},
+	}
+
+	queries, formulas := bindIndexedQueries(values)
+
+	zero := 0
+	expected := []api.LogsQuery{{Measure: "count", TopN: &zero}}
+	if d := cmp.Diff(expected, queries); d != "" {
+		t.Errorf("Unexpected queries:\n%v", d)
+	}
+	if len(formulas) != 0 {
+		t.Errorf("Expected no formulas but got %v", formulas)
+	}
+
+	// Keys that weren't bound are left in values so they end up in ExtraParams
+	expectedValues := url.Values{
+		"agg_m_1000000000": []string{"count"},
+		"formula_26":       []string{"a / b"},
+	}
+	if d := cmp.Diff(expectedValues, values); d != "" {
+		t.Errorf("Unexpected remaining values:\n%v", d)
+	}
+}
@@ -0,0 +1,77 @@
+package ddog
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/jlewi/ddctl/api"
+	"github.com/pkg/errors"
+)
+
+const (
+	networkPath = "/network"
+)
+
+var (
+	networkPages = []string{api.NetworkPageAnalytics, api.NetworkPageMap}
+)
+
+// BuildNetworkURL builds the URL for a Network Performance Monitoring link.
+func BuildNetworkURL(link *api.DatadogNetwork) (string, error) {
+	page := link.Page
+	if page == "" {
+		page = api.NetworkPageAnalytics
+	}
+
+	if !slices.Contains(networkPages, page) {
+		return "", errors.Errorf("Unsupported network page %v; supported pages are %v", page, networkPages)
+	}
+
+	queryParams := url.Values{}
+	addString(queryParams, "groupby_source", strings.Join(link.SourceGroupBy, ","))
+	addString(queryParams, "groupby_dest", strings.Join(link.DestinationGroupBy, ","))
+	addString(queryParams, "source_query", link.SourceFilter)
+	addString(queryParams, "dest_query", link.DestinationFilter)
+	addString(queryParams, "metric", link.Metric)
+	if err := addTimeRange(queryParams, link.FromTS, link.ToTS); err != nil {
+		return "", err
+	}
+	addExtraParams(queryParams, link.ExtraParams)
+
+	encodedQuery := queryParams.Encode()
+	u := fmt.Sprintf("%s%s/%s?%s", link.BaseURL, networkPath, page, encodedQuery)
+	return u, nil
+}
+
+// NetworkURLToLink converts a network URL to a DatadogNetwork link.
+func NetworkURLToLink(u url.URL) (*api.DatadogNetwork, error) {
+	link := &api.DatadogNetwork{
+		APIVersion: api.NetworkGVK.GroupVersion().String(),
+		Kind:       api.NetworkGVK.Kind,
+		BaseURL:    getBaseURL(u),
+	}
+
+	page := strings.Trim(strings.TrimPrefix(u.Path, networkPath), "/")
+	if page == "" {
+		page = api.NetworkPageAnalytics
+	}
+	if !slices.Contains(networkPages, page) {
+		return nil, errors.Errorf("Unsupported network page %v; supported pages are %v", page, networkPages)
+	}
+	link.Page = page
+
+	queryParamMap := map[string]queryValHandler{
+		"groupby_source": bindToStringSlice(&link.SourceGroupBy),
+		"groupby_dest":   bindToStringSlice(&link.DestinationGroupBy),
+		"source_query":   bindToString(&link.SourceFilter),
+		"dest_query":     bindToString(&link.DestinationFilter),
+		"metric":         bindToString(&link.Metric),
+		"from_ts":        bindToString(&link.FromTS),
+		"to_ts":          bindToString(&link.ToTS),
+	}
+
+	link.ExtraParams = bindQueryParams(u.Query(), queryParamMap)
+	return link, nil
+}
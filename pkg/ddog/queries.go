@@ -0,0 +1,175 @@
+package ddog
+
+import (
+	"github.com/jlewi/ddctl/api"
+	"github.com/jlewi/ddctl/pkg/query"
+	"github.com/pkg/errors"
+)
+
+// QueryField is a field of a link containing a Datadog search query.
+type QueryField struct {
+	// Name is the name of the field in the YAML representation of the link e.g. query
+	Name  string
+	Value *string
+}
+
+// LinkQueryFields returns the fields of the link containing search queries. Links without a query
+// (e.g. DatadogTrace) return an empty list.
+func LinkQueryFields(link any) ([]QueryField, error) {
+	switch v := link.(type) {
+	case *api.DatadogLink:
+		return []QueryField{{Name: "query", Value: &v.Query}}, nil
+	case *api.DatadogErrorTracking:
+		return []QueryField{{Name: "query", Value: &v.Query}}, nil
+	case *api.DatadogDatabase:
+		return []QueryField{{Name: "query", Value: &v.Query}}, nil
+	case *api.DatadogCI:
+		return []QueryField{{Name: "query", Value: &v.Query}}, nil
+	case *api.DatadogSecuritySignal:
+		return []QueryField{{Name: "query", Value: &v.Query}}, nil
+	case *api.DatadogLLMTrace:
+		return []QueryField{{Name: "query", Value: &v.Query}}, nil
+	case *api.DatadogServerless:
+		return []QueryField{{Name: "query", Value: &v.Query}}, nil
+	case *api.DatadogNetwork:
+		return []QueryField{{Name: "sourceFilter", Value: &v.SourceFilter}, {Name: "destinationFilter", Value: &v.DestinationFilter}}, nil
+	case *api.DatadogAudit:
+		return []QueryField{{Name: "query", Value: &v.Query}}, nil
+	case *api.DatadogTraceSearch:
+		return []QueryField{{Name: "query", Value: &v.Query}}, nil
+	case *api.DatadogTrace, *api.DatadogCatalogEntity:
+		return nil, nil
+	default:
+		return nil, errors.Errorf("Unsupported link type %T", link)
+	}
+}
+
+// FormatLinkQueries replaces the queries in the link with their canonical form; see query.Format.
+func FormatLinkQueries(link any) error {
+	fields, err := LinkQueryFields(link)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range fields {
+		if *f.Value == "" {
+			continue
+		}
+		formatted, err := query.Format(*f.Value)
+		if err != nil {
+			return errors.Wrapf(err, "Failed to format %v", f.Name)
+		}
+		*f.Value = formatted
+	}
+	return nil
+}
+
+// LinkFinding is a finding in one of the queries of a link.
+type LinkFinding struct {
+	// Field is the name of the field containing the query e.g. query
+	Field string
+	// Query is the value of the field
+	Query string
+	query.Finding
+}
+
+// LintLink lints the queries in the link; see query.Lint. The rules in query.LogRules are only applied to
+// links that search logs.
+func LintLink(link any) ([]LinkFinding, error) {
+	fields, err := LinkQueryFields(link)
+	if err != nil {
+		return nil, err
+	}
+
+	isLogs := false
+	switch link.(type) {
+	case *api.DatadogLink, *api.DatadogAudit:
+		isLogs = true
+	}
+
+	var findings []LinkFinding
+	for _, f := range fields {
+		findingsForField, err := query.Lint(*f.Value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to lint %v", f.Name)
+		}
+		for _, finding := range findingsForField {
+			if query.LogRules[finding.Rule] && !isLogs {
+				continue
+			}
+			findings = append(findings, LinkFinding{Field: f.Name, Query: *f.Value, Finding: finding})
+		}
+	}
+	return findings, nil
+}
+
+// QueryEdit is a set of changes to a query. Each change is a single term e.g. env:staging or -service:web.
+type QueryEdit struct {
+	// Add are the terms to AND with the query
+	Add []string
+	// Remove are the terms to remove from the query
+	Remove []string
+	// Replace are fields whose value should be replaced e.g. @http.method:POST
+	Replace []string
+}
+
+// EditQuery applies the edit to the query; removals are applied first followed by replacements and additions.
+// See query.RemoveTerm, query.ReplaceTerm and query.AddTerm.
+func EditQuery(q string, edit QueryEdit) (string, error) {
+	n, err := query.Parse(q)
+	if err != nil {
+		return "", err
+	}
+
+	for _, t := range edit.Remove {
+		term, err := query.Parse(t)
+		if err != nil {
+			return "", errors.Wrapf(err, "Failed to parse term %v", t)
+		}
+		n, err = query.RemoveTerm(n, term)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	for _, t := range edit.Replace {
+		term, err := query.Parse(t)
+		if err != nil {
+			return "", errors.Wrapf(err, "Failed to parse term %v", t)
+		}
+		n, err = query.ReplaceTerm(n, term)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	for _, t := range edit.Add {
+		term, err := query.Parse(t)
+		if err != nil {
+			return "", errors.Wrapf(err, "Failed to parse term %v", t)
+		}
+		n = query.AddTerm(n, term)
+	}
+	return query.Print(n), nil
+}
+
+// EditLinkQuery applies the edit to the query in the named field of the link e.g. query.
+func EditLinkQuery(link any, field string, edit QueryEdit) error {
+	fields, err := LinkQueryFields(link)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range fields {
+		if f.Name != field {
+			continue
+		}
+		edited, err := EditQuery(*f.Value, edit)
+		if err != nil {
+			return errors.Wrapf(err, "Failed to edit %v", f.Name)
+		}
+		*f.Value = edited
+		return nil
+	}
+	return errors.Errorf("%T doesn't have a query field %v", link, field)
+}
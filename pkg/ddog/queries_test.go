@@ -0,0 +1,86 @@
+package ddog
+
+import (
+	"testing"
+
+	"github.com/jlewi/ddctl/api"
+	"github.com/jlewi/ddctl/pkg/query"
+)
+
+func TestFormatLinkQueries(t *testing.T) {
+	link := &api.DatadogNetwork{
+		SourceFilter:      `service:"web" AND env:prod`,
+		DestinationFilter: "NOT NOT service:db",
+	}
+
+	if err := FormatLinkQueries(link); err != nil {
+		t.Fatalf("Failed to format queries: %+v", err)
+	}
+
+	if link.SourceFilter != "env:prod service:web" {
+		t.Errorf("SourceFilter doesn't match; got %v", link.SourceFilter)
+	}
+	if link.DestinationFilter != "service:db" {
+		t.Errorf("DestinationFilter doesn't match; got %v", link.DestinationFilter)
+	}
+
+	invalid := &api.DatadogLink{Query: "a OR"}
+	if err := FormatLinkQueries(invalid); err == nil {
+		t.Errorf("Expected an error formatting an invalid query")
+	}
+}
+
+func TestLintLink(t *testing.T) {
+	link := &api.DatadogLink{Query: "service:web -service:web"}
+	findings, err := LintLink(link)
+	if err != nil {
+		t.Fatalf("Failed to lint link: %+v", err)
+	}
+
+	if len(findings) != 1 {
+		t.Fatalf("Expected 1 finding but got %v", findings)
+	}
+	if findings[0].Field != "query" || findings[0].Rule != query.RuleContradiction {
+		t.Errorf("Finding doesn't match; got %+v", findings[0])
+	}
+
+	// Log rules don't apply to other kinds of links
+	ci := &api.DatadogCI{Query: "status:ERROR @test.name:*login"}
+	findings, err = LintLink(ci)
+	if err != nil {
+		t.Fatalf("Failed to lint link: %+v", err)
+	}
+	if len(findings) != 0 {
+		t.Errorf("Expected no findings but got %v", findings)
+	}
+
+	logs := &api.DatadogLink{Query: "status:ERROR"}
+	findings, err = LintLink(logs)
+	if err != nil {
+		t.Fatalf("Failed to lint link: %+v", err)
+	}
+	if len(findings) != 1 || findings[0].Rule != query.RuleStatusCase {
+		t.Errorf("Expected a %v finding but got %v", query.RuleStatusCase, findings)
+	}
+}
+
+func TestEditLinkQuery(t *testing.T) {
+	link := &api.DatadogLink{Query: "env:prod service:feserver* @http.method:GET"}
+	edit := QueryEdit{
+		Add:     []string{"env:staging"},
+		Remove:  []string{"service:feserver*"},
+		Replace: []string{"@http.method:POST"},
+	}
+	if err := EditLinkQuery(link, "query", edit); err != nil {
+		t.Fatalf("Failed to edit query: %+v", err)
+	}
+
+	expected := "env:prod @http.method:POST env:staging"
+	if link.Query != expected {
+		t.Errorf("Got %v;\n Want %v", link.Query, expected)
+	}
+
+	if err := EditLinkQuery(&api.DatadogTrace{}, "query", edit); err == nil {
+		t.Errorf("Expected an error editing a link without a query")
+	}
+}
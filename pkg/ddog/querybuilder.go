@@ -0,0 +1,191 @@
+package ddog
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jlewi/ddctl/pkg/query"
+)
+
+// QueryBuilder builds a Datadog search query from Go code. The terms added to the builder are ANDed together
+// and values are quoted or escaped as needed so they match literally. For example
+//
+//	Q().Tag("env", "prod").Facet("@http.status_code").Range(500, 599).String()
+//
+// returns env:prod @http.status_code:[500 TO 599]
+type QueryBuilder struct {
+	terms []query.Node
+}
+
+// Q returns an empty QueryBuilder.
+func Q() *QueryBuilder {
+	return &QueryBuilder{}
+}
+
+// FromQuery returns a QueryBuilder starting with the terms of an existing query.
+func FromQuery(q string) (*QueryBuilder, error) {
+	n, err := query.Parse(q)
+	if err != nil {
+		return nil, err
+	}
+	return FromNode(n), nil
+}
+
+// FromNode returns a QueryBuilder starting with the terms of a parsed query.
+func FromNode(n query.Node) *QueryBuilder {
+	b := Q()
+	switch v := n.(type) {
+	case nil:
+	case *query.And:
+		b.terms = append(b.terms, v.Operands...)
+	default:
+		b.terms = append(b.terms, v)
+	}
+	return b
+}
+
+// Text adds free text matching value literally.
+func (b *QueryBuilder) Text(value string) *QueryBuilder {
+	return b.add(query.Literal(value))
+}
+
+// Wildcard adds free text in which * and ? are wildcards.
+func (b *QueryBuilder) Wildcard(pattern string) *QueryBuilder {
+	return b.add(query.Wildcard(pattern))
+}
+
+// Tag adds a term matching a tag or reserved attribute (e.g. env, service or status) with the given value.
+func (b *QueryBuilder) Tag(key string, value string) *QueryBuilder {
+	return b.add(&query.Field{Key: key, Value: query.Literal(value)})
+}
+
+// Facet returns a builder for a term matching the facet. The @ prefix is added to the key if it is missing.
+func (b *QueryBuilder) Facet(key string) *FacetBuilder {
+	if !strings.HasPrefix(key, "@") {
+		key = "@" + key
+	}
+	return &FacetBuilder{parent: b, key: key}
+}
+
+// Term adds a parsed term e.g. one built with the query package.
+func (b *QueryBuilder) Term(n query.Node) *QueryBuilder {
+	if n == nil {
+		return b
+	}
+	return b.add(n)
+}
+
+// Not adds the negation of the terms in q.
+func (b *QueryBuilder) Not(q *QueryBuilder) *QueryBuilder {
+	n := q.Node()
+	if n == nil {
+		return b
+	}
+	return b.add(&query.Not{Operand: n})
+}
+
+// Or adds a term matching any of the queries.
+func (b *QueryBuilder) Or(qs ...*QueryBuilder) *QueryBuilder {
+	operands := make([]query.Node, 0, len(qs))
+	for _, q := range qs {
+		if n := q.Node(); n != nil {
+			operands = append(operands, n)
+		}
+	}
+
+	switch len(operands) {
+	case 0:
+		return b
+	case 1:
+		return b.add(operands[0])
+	}
+	return b.add(&query.Or{Operands: operands})
+}
+
+// Node returns the AST for the query. It returns nil if the query is empty.
+func (b *QueryBuilder) Node() query.Node {
+	switch len(b.terms) {
+	case 0:
+		return nil
+	case 1:
+		return b.terms[0]
+	}
+	return &query.And{Operands: b.terms, Implicit: true}
+}
+
+// String returns the query string.
+func (b *QueryBuilder) String() string {
+	return query.Print(b.Node())
+}
+
+func (b *QueryBuilder) add(n query.Node) *QueryBuilder {
+	b.terms = append(b.terms, n)
+	return b
+}
+
+// FacetBuilder adds a term matching a facet to a QueryBuilder.
+type FacetBuilder struct {
+	parent *QueryBuilder
+	key    string
+}
+
+// Eq matches the facet with the given value.
+func (f *FacetBuilder) Eq(value string) *QueryBuilder {
+	return f.add(query.Literal(value))
+}
+
+// Wildcard matches the facet with a pattern in which * and ? are wildcards.
+func (f *FacetBuilder) Wildcard(pattern string) *QueryBuilder {
+	return f.add(query.Wildcard(pattern))
+}
+
+// In matches the facet with any of the values.
+func (f *FacetBuilder) In(values ...string) *QueryBuilder {
+	if len(values) == 1 {
+		return f.Eq(values[0])
+	}
+
+	operands := make([]query.Node, 0, len(values))
+	for _, v := range values {
+		operands = append(operands, query.Literal(v))
+	}
+	return f.add(&query.Group{Expr: &query.Or{Operands: operands}})
+}
+
+// Exists matches logs that have the facet.
+func (f *FacetBuilder) Exists() *QueryBuilder {
+	return f.add(&query.Text{Value: "*"})
+}
+
+// Range matches values between low and high inclusive. Use "*" for an unbounded side.
+func (f *FacetBuilder) Range(low any, high any) *QueryBuilder {
+	return f.add(&query.Range{Low: fmt.Sprint(low), High: fmt.Sprint(high)})
+}
+
+// Gt matches values greater than value.
+func (f *FacetBuilder) Gt(value any) *QueryBuilder {
+	return f.compare(">", value)
+}
+
+// Gte matches values greater than or equal to value.
+func (f *FacetBuilder) Gte(value any) *QueryBuilder {
+	return f.compare(">=", value)
+}
+
+// Lt matches values less than value.
+func (f *FacetBuilder) Lt(value any) *QueryBuilder {
+	return f.compare("<", value)
+}
+
+// Lte matches values less than or equal to value.
+func (f *FacetBuilder) Lte(value any) *QueryBuilder {
+	return f.compare("<=", value)
+}
+
+func (f *FacetBuilder) compare(op string, value any) *QueryBuilder {
+	return f.add(&query.Comparison{Op: op, Value: fmt.Sprint(value)})
+}
+
+func (f *FacetBuilder) add(value query.Node) *QueryBuilder {
+	return f.parent.add(&query.Field{Key: f.key, Value: value})
+}
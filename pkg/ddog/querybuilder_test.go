@@ -0,0 +1,79 @@
+package ddog
+
+import (
+	"testing"
+
+	"github.com/jlewi/ddctl/pkg/query"
+)
+
+func TestQueryBuilder(t *testing.T) {
+	type testCase struct {
+		Name     string
+		Builder  *QueryBuilder
+		Expected string
+	}
+
+	cases := []testCase{
+		{
+			Name:     "empty",
+			Builder:  Q(),
+			Expected: "",
+		},
+		{
+			Name:     "tags-and-range",
+			Builder:  Q().Tag("env", "prod").Facet("@http.status_code").Range(500, 599),
+			Expected: "env:prod @http.status_code:[500 TO 599]",
+		},
+		{
+			Name:     "escaping",
+			Builder:  Q().Text("connection reset").Facet("url").Eq("http://example.com/a b").Facet("@msg").Eq(`say "hi"`),
+			Expected: `"connection reset" @url:"http://example.com/a b" @msg:"say \"hi\""`,
+		},
+		{
+			Name:     "wildcards",
+			Builder:  Q().Tag("service", "web*").Facet("@path").Wildcard("/api/*"),
+			Expected: `service:"web*" @path:\/api\/*`,
+		},
+		{
+			Name:     "not-and-or",
+			Builder:  Q().Tag("service", "web").Not(Q().Tag("status", "info")).Or(Q().Facet("@duration").Gt(1000), Q().Tag("status", "error").Tag("env", "prod")),
+			Expected: "service:web -status:info (@duration:>1000 OR status:error env:prod)",
+		},
+		{
+			Name:     "not-multiple-terms",
+			Builder:  Q().Not(Q().Tag("env", "staging").Facet("@http.method").In("GET", "HEAD")),
+			Expected: "-(env:staging @http.method:(GET OR HEAD))",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			actual := c.Builder.String()
+			if actual != c.Expected {
+				t.Errorf("Got %v;\n Want %v", actual, c.Expected)
+			}
+
+			// The query must parse back to the same AST.
+			n, err := query.Parse(actual)
+			if err != nil {
+				t.Fatalf("Failed to parse %v: %v", actual, err)
+			}
+			if query.Print(n) != actual {
+				t.Errorf("Query doesn't round trip; got %v; want %v", query.Print(n), actual)
+			}
+		})
+	}
+}
+
+func TestFromQuery(t *testing.T) {
+	b, err := FromQuery("service:web (a OR b)")
+	if err != nil {
+		t.Fatalf("Failed to parse query: %v", err)
+	}
+
+	actual := b.Tag("env", "prod").String()
+	expected := "service:web (a OR b) env:prod"
+	if actual != expected {
+		t.Errorf("Got %v;\n Want %v", actual, expected)
+	}
+}
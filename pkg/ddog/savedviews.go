@@ -0,0 +1,200 @@
+package ddog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/jlewi/ddctl/api"
+	"github.com/jlewi/ddctl/pkg/query"
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultAPIURL is the URL of the Datadog API for the US1 site.
+	DefaultAPIURL = "https://api.datadoghq.com"
+
+	apiKeyHeader = "DD-API-KEY"
+	appKeyHeader = "DD-APPLICATION-KEY"
+)
+
+// SavedView is a logs saved view.
+type SavedView struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Query   string   `json:"query"`
+	Columns []string `json:"columns"`
+	Indexes []string `json:"indexes"`
+	// Facets maps a facet (e.g. status or @http.status_code) to the values selected in the facet panel.
+	Facets map[string][]string `json:"facets"`
+}
+
+// savedViewResponse is the body of the response to fetching a saved view.
+type savedViewResponse struct {
+	Data struct {
+		ID         string    `json:"id"`
+		Attributes SavedView `json:"attributes"`
+	} `json:"data"`
+}
+
+// SavedViewClient fetches logs saved views from the Datadog API.
+type SavedViewClient struct {
+	// APIURL is the base URL of the Datadog API e.g. https://api.datadoghq.com
+	APIURL string
+	APIKey string
+	AppKey string
+	Client *http.Client
+}
+
+// NewSavedViewClient creates a new client. If apiURL is empty DefaultAPIURL is used.
+func NewSavedViewClient(apiURL string, apiKey string, appKey string) (*SavedViewClient, error) {
+	if apiKey == "" || appKey == "" {
+		return nil, errors.New("An API key and an application key are required to fetch saved views")
+	}
+	if apiURL == "" {
+		apiURL = DefaultAPIURL
+	}
+	return &SavedViewClient{
+		APIURL: strings.TrimSuffix(apiURL, "/"),
+		APIKey: apiKey,
+		AppKey: appKey,
+		Client: http.DefaultClient,
+	}, nil
+}
+
+// Get fetches the saved view with the given ID.
+func (c *SavedViewClient) Get(ctx context.Context, id string) (*SavedView, error) {
+	u := fmt.Sprintf("%s/api/v1/logs/views/%s", c.APIURL, url.PathEscape(id))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to create request for %v", u)
+	}
+	req.Header.Set(apiKeyHeader, c.APIKey)
+	req.Header.Set(appKeyHeader, c.AppKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to fetch saved view %v", id)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to read response for saved view %v", id)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("Failed to fetch saved view %v; status %v; body: %v", id, resp.Status, string(body))
+	}
+
+	r := &savedViewResponse{}
+	if err := json.Unmarshal(body, r); err != nil {
+		return nil, errors.Wrapf(err, "Failed to unmarshal saved view %v", id)
+	}
+
+	view := r.Data.Attributes
+	view.ID = r.Data.ID
+	return &view, nil
+}
+
+// ResolveSavedView inlines the saved view referenced by the link so the link no longer depends on it.
+// Values already set on the link take precedence over the saved view. The facet selections of the view
+// are added to the query. The SavedView field is cleared once the view has been inlined.
+func (c *SavedViewClient) ResolveSavedView(ctx context.Context, link *api.DatadogLink) error {
+	if link.SavedView == "" {
+		return nil
+	}
+
+	view, err := c.Get(ctx, link.SavedView)
+	if err != nil {
+		return err
+	}
+
+	InlineSavedView(link, view)
+	return nil
+}
+
+// InlineSavedView copies the query, columns, indexes and facet selections of view into link.
+//
+// If the link has a query it replaces the query of the view because the query in a URL is the search bar as the
+// user left it. The facet selections of the view are ANDed with the query either way since Datadog shows them
+// outside the search bar.
+func InlineSavedView(link *api.DatadogLink, view *SavedView) {
+	query := link.Query
+	if query == "" {
+		query = view.Query
+	}
+
+	terms := make([]string, 0, len(view.Facets)+1)
+	if query != "" {
+		// Group the query so the facet terms apply to all of it and not just the last OR clause
+		if len(view.Facets) > 0 && hasTopLevelOr(query) {
+			query = "(" + query + ")"
+		}
+		terms = append(terms, query)
+	}
+	// Iterate in sorted order so the query is deterministic
+	for _, facet := range slices.Sorted(maps.Keys(view.Facets)) {
+		if term := facetSelectionTerm(facet, view.Facets[facet]); term != "" {
+			terms = append(terms, term)
+		}
+	}
+	link.Query = strings.Join(terms, " ")
+
+	if len(link.Columns) == 0 {
+		link.Columns = view.Columns
+	}
+	if len(link.Indexes) == 0 {
+		link.Indexes = view.Indexes
+	}
+	link.SavedView = ""
+}
+
+// hasTopLevelOr returns true if the query has an OR that isn't inside parentheses, so ANDing a term with the
+// query requires grouping it first. A lowercase or counts since it was most likely meant as OR.
+// Queries that can't be parsed are assumed to need grouping.
+func hasTopLevelOr(q string) bool {
+	n, err := query.Parse(q)
+	if err != nil {
+		return true
+	}
+	found := false
+	query.Walk(n, func(n query.Node) bool {
+		switch v := n.(type) {
+		case *query.Or:
+			found = true
+		case *query.Text:
+			if !v.Quoted && strings.EqualFold(v.Value, "or") {
+				found = true
+			}
+		case *query.Group, *query.Field:
+			return false
+		}
+		return !found
+	})
+	return found
+}
+
+// facetSelectionTerm returns the query term matching any of the values selected for a facet.
+func facetSelectionTerm(facet string, values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, v := range values {
+		quoted = append(quoted, quoteTermValue(v))
+	}
+
+	switch len(quoted) {
+	case 0:
+		return ""
+	case 1:
+		return facet + ":" + quoted[0]
+	default:
+		return facet + ":(" + strings.Join(quoted, " OR ") + ")"
+	}
+}
@@ -0,0 +1,150 @@
+package ddog
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jlewi/ddctl/api"
+)
+
+func TestResolveSavedView(t *testing.T) {
+	type testCase struct {
+		Name     string
+		Input    *api.DatadogLink
+		Expected *api.DatadogLink
+	}
+
+	cases := []testCase{
+		{
+			Name: "basic",
+			Input: &api.DatadogLink{
+				BaseURL:   "https://acme.datadoghq.com",
+				SavedView: "1234",
+				FromTS:    "now-1h",
+			},
+			Expected: &api.DatadogLink{
+				BaseURL: "https://acme.datadoghq.com",
+				Query:   `service:feserver @http.method:(POST OR "GET /healthz") status:error`,
+				Columns: []string{"host", "service"},
+				Indexes: []string{"main"},
+				FromTS:  "now-1h",
+			},
+		},
+		{
+			Name: "link-overrides-view",
+			Input: &api.DatadogLink{
+				SavedView: "1234",
+				Query:     "service:a OR service:b",
+				Columns:   []string{"host"},
+			},
+			Expected: &api.DatadogLink{
+				Query:   `(service:a OR service:b) @http.method:(POST OR "GET /healthz") status:error`,
+				Columns: []string{"host"},
+				Indexes: []string{"main"},
+			},
+		},
+		{
+			Name: "lowercase-or",
+			Input: &api.DatadogLink{
+				SavedView: "1234",
+				Query:     "service:a or\tservice:b",
+			},
+			Expected: &api.DatadogLink{
+				Query:   `(service:a or	service:b) @http.method:(POST OR "GET /healthz") status:error`,
+				Columns: []string{"host", "service"},
+				Indexes: []string{"main"},
+			},
+		},
+		{
+			Name: "lowercase-or-free-text",
+			Input: &api.DatadogLink{
+				SavedView: "1234",
+				Query:     "foo or bar",
+			},
+			Expected: &api.DatadogLink{
+				Query:   `(foo or bar) @http.method:(POST OR "GET /healthz") status:error`,
+				Columns: []string{"host", "service"},
+				Indexes: []string{"main"},
+			},
+		},
+		{
+			Name: "grouped-or",
+			Input: &api.DatadogLink{
+				SavedView: "1234",
+				Query:     "env:prod @http.method:(GET OR PUT)",
+			},
+			Expected: &api.DatadogLink{
+				Query:   `env:prod @http.method:(GET OR PUT) @http.method:(POST OR "GET /healthz") status:error`,
+				Columns: []string{"host", "service"},
+				Indexes: []string{"main"},
+			},
+		},
+		{
+			Name: "no-saved-view",
+			Input: &api.DatadogLink{
+				Query: "service:feserver",
+			},
+			Expected: &api.DatadogLink{
+				Query: "service:feserver",
+			},
+		},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(apiKeyHeader) != "api-key" || r.Header.Get(appKeyHeader) != "app-key" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.Path != "/api/v1/logs/views/1234" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := w.Write([]byte(`{
+  "data": {
+    "id": "1234",
+    "type": "logs_view",
+    "attributes": {
+      "name": "feserver errors",
+      "query": "service:feserver",
+      "columns": ["host", "service"],
+      "indexes": ["main"],
+      "facets": {
+        "status": ["error"],
+        "@http.method": ["POST", "GET /healthz"]
+      }
+    }
+  }
+}`)); err != nil {
+			t.Errorf("Failed to write response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewSavedViewClient(server.URL, "api-key", "app-key")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			if err := client.ResolveSavedView(context.Background(), c.Input); err != nil {
+				t.Fatalf("Failed to resolve saved view: %v", err)
+			}
+
+			if d := cmp.Diff(c.Expected, c.Input); d != "" {
+				t.Errorf("Link does not match; diff\n%v", d)
+			}
+		})
+	}
+
+	t.Run("not-found", func(t *testing.T) {
+		link := &api.DatadogLink{SavedView: "5678"}
+		if err := client.ResolveSavedView(context.Background(), link); err == nil {
+			t.Errorf("Expected an error for a saved view that doesn't exist")
+		}
+	})
+}
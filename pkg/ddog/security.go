@@ -0,0 +1,56 @@
+package ddog
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/jlewi/ddctl/api"
+)
+
+const (
+	securitySignalsPath = "/security/signals"
+)
+
+// securitySignalFacets returns the query facets for the structured fields of the link.
+func securitySignalFacets(link *api.DatadogSecuritySignal) []facetField {
+	return []facetField{
+		{key: "status", field: &link.Severity},
+		{key: "@workflow.triage.state", field: &link.Status},
+		{key: "@workflow.rule.id", field: &link.RuleID},
+	}
+}
+
+// BuildSecuritySignalURL builds the URL for a security signals link.
+func BuildSecuritySignalURL(link *api.DatadogSecuritySignal) (string, error) {
+	queryParams := url.Values{}
+	addString(queryParams, "query", buildFacetQuery(securitySignalFacets(link), link.Query))
+	addString(queryParams, "event", link.SignalID)
+	if err := addTimeRange(queryParams, link.FromTS, link.ToTS); err != nil {
+		return "", err
+	}
+	addExtraParams(queryParams, link.ExtraParams)
+
+	encodedQuery := queryParams.Encode()
+	u := fmt.Sprintf("%s%s?%s", link.BaseURL, securitySignalsPath, encodedQuery)
+	return u, nil
+}
+
+// SecuritySignalURLToLink converts a security signals URL to a DatadogSecuritySignal link.
+func SecuritySignalURLToLink(u url.URL) (*api.DatadogSecuritySignal, error) {
+	link := &api.DatadogSecuritySignal{
+		APIVersion: api.SecuritySignalGVK.GroupVersion().String(),
+		Kind:       api.SecuritySignalGVK.Kind,
+		BaseURL:    getBaseURL(u),
+	}
+
+	queryParamMap := map[string]queryValHandler{
+		"query":   bindToString(&link.Query),
+		"event":   bindToString(&link.SignalID),
+		"from_ts": bindToString(&link.FromTS),
+		"to_ts":   bindToString(&link.ToTS),
+	}
+
+	link.ExtraParams = bindQueryParams(u.Query(), queryParamMap)
+	link.Query = extractFacets(securitySignalFacets(link), link.Query)
+	return link, nil
+}
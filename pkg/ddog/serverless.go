@@ -0,0 +1,79 @@
+package ddog
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/jlewi/ddctl/api"
+	"github.com/pkg/errors"
+)
+
+const (
+	functionsPath = "/functions"
+	arnPrefix     = "arn:"
+)
+
+// serverlessFacets returns the query facets for the structured fields of the link.
+func serverlessFacets(link *api.DatadogServerless) []facetField {
+	return []facetField{
+		{key: "region", field: &link.Region},
+		{key: "env", field: &link.Env},
+		{key: "version", field: &link.Version},
+	}
+}
+
+// BuildServerlessURL builds the URL for a serverless function link.
+func BuildServerlessURL(link *api.DatadogServerless) (string, error) {
+	if link.FunctionName != "" && link.ARN != "" {
+		return "", errors.Errorf("At most one of functionName and arn can be set; got functionName %v and arn %v", link.FunctionName, link.ARN)
+	}
+
+	if link.ARN != "" && !strings.HasPrefix(link.ARN, arnPrefix) {
+		return "", errors.Errorf("arn %v doesn't start with %v", link.ARN, arnPrefix)
+	}
+
+	p := functionsPath
+	if id := link.FunctionName + link.ARN; id != "" {
+		p = functionsPath + "/" + url.PathEscape(id)
+	}
+
+	queryParams := url.Values{}
+	addString(queryParams, "query", buildFacetQuery(serverlessFacets(link), link.Query))
+	addString(queryParams, "tab", link.Tab)
+	if err := addTimeRange(queryParams, link.FromTS, link.ToTS); err != nil {
+		return "", err
+	}
+	addExtraParams(queryParams, link.ExtraParams)
+
+	encodedQuery := queryParams.Encode()
+	u := fmt.Sprintf("%s%s?%s", link.BaseURL, p, encodedQuery)
+	return u, nil
+}
+
+// ServerlessURLToLink converts a serverless URL to a DatadogServerless link.
+func ServerlessURLToLink(u url.URL) (*api.DatadogServerless, error) {
+	link := &api.DatadogServerless{
+		APIVersion: api.ServerlessGVK.GroupVersion().String(),
+		Kind:       api.ServerlessGVK.Kind,
+		BaseURL:    getBaseURL(u),
+	}
+
+	id := strings.Trim(strings.TrimPrefix(u.Path, functionsPath), "/")
+	if strings.HasPrefix(id, arnPrefix) {
+		link.ARN = id
+	} else {
+		link.FunctionName = id
+	}
+
+	queryParamMap := map[string]queryValHandler{
+		"query":   bindToString(&link.Query),
+		"tab":     bindToString(&link.Tab),
+		"from_ts": bindToString(&link.FromTS),
+		"to_ts":   bindToString(&link.ToTS),
+	}
+
+	link.ExtraParams = bindQueryParams(u.Query(), queryParamMap)
+	link.Query = extractFacets(serverlessFacets(link), link.Query)
+	return link, nil
+}
@@ -0,0 +1,120 @@
+package ddog
+
+import (
+	"strconv"
+	"strings"
+)
+
+// facetField binds a key in a Datadog search query (e.g. @git.branch) to a field in a link.
+// It lets links expose commonly used filters as structured fields while Datadog only has a single query parameter.
+type facetField struct {
+	key   string
+	field *string
+}
+
+// buildFacetQuery returns a query consisting of a key:value term for every non-empty facet followed by query.
+func buildFacetQuery(facets []facetField, query string) string {
+	terms := make([]string, 0, len(facets)+1)
+	for _, f := range facets {
+		if *f.field == "" {
+			continue
+		}
+		terms = append(terms, f.key+":"+quoteTermValue(*f.field))
+	}
+
+	if query != "" {
+		terms = append(terms, query)
+	}
+	return strings.Join(terms, " ")
+}
+
+// extractFacets is the inverse of buildFacetQuery. It removes the terms matching the facets from the query,
+// stores their values in the fields and returns the remainder of the query.
+//
+// Terms are only extracted when the query is a simple conjunction of terms; if the query contains boolean OR
+// or parentheses it is returned unchanged because removing a term could change the meaning of the query.
+// Terms next to an operator such as NOT are left in the query for the same reason.
+func extractFacets(facets []facetField, query string) string {
+	tokens := splitTerms(query)
+	for _, t := range tokens {
+		if t == "OR" || strings.HasPrefix(t, "(") || strings.HasSuffix(t, ")") {
+			return query
+		}
+	}
+
+	remaining := make([]string, 0, len(tokens))
+	for i, t := range tokens {
+		bound := (i == 0 || !isOperator(tokens[i-1])) && (i == len(tokens)-1 || !isOperator(tokens[i+1])) && bindTerm(facets, t)
+		if !bound {
+			remaining = append(remaining, t)
+		}
+	}
+	return strings.Join(remaining, " ")
+}
+
+// isOperator returns true if the token is a boolean operator that applies to the terms next to it.
+func isOperator(token string) bool {
+	switch strings.ToUpper(token) {
+	case "NOT", "OR", "AND", "-":
+		return true
+	}
+	return false
+}
+
+// bindTerm sets the field of the facet matching term. It returns false if no facet matched
+// or the facet was already set; e.g. because the query contains the same key twice.
+func bindTerm(facets []facetField, term string) bool {
+	for _, f := range facets {
+		prefix := f.key + ":"
+		if !strings.HasPrefix(term, prefix) || *f.field != "" {
+			continue
+		}
+		value := strings.TrimPrefix(term, prefix)
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		}
+		if value == "" {
+			return false
+		}
+		*f.field = value
+		return true
+	}
+	return false
+}
+
+// splitTerms splits a query on whitespace; whitespace inside double quotes doesn't split a term.
+func splitTerms(query string) []string {
+	var terms []string
+	var current strings.Builder
+	inQuotes := false
+	escaped := false
+	for _, r := range query {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '"':
+			inQuotes = !inQuotes
+		case !inQuotes && (r == ' ' || r == '\t' || r == '\n'):
+			if current.Len() > 0 {
+				terms = append(terms, current.String())
+				current.Reset()
+			}
+			continue
+		}
+		current.WriteRune(r)
+	}
+	if current.Len() > 0 {
+		terms = append(terms, current.String())
+	}
+	return terms
+}
+
+// quoteTermValue quotes a value if it contains whitespace so it is treated as a single value.
+func quoteTermValue(value string) string {
+	if strings.ContainsAny(value, " \t\n") {
+		return strconv.Quote(value)
+	}
+	return value
+}
@@ -0,0 +1,119 @@
+package ddog
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/jlewi/ddctl/api"
+)
+
+func Test_extractFacets(t *testing.T) {
+	type testCase struct {
+		Name           string
+		Query          string
+		ExpectedBranch string
+		ExpectedSHA    string
+		ExpectedQuery  string
+	}
+
+	cases := []testCase{
+		{
+			Name:           "basic",
+			Query:          "@git.branch:main @test.status:fail @git.commit.sha:abcd",
+			ExpectedBranch: "main",
+			ExpectedSHA:    "abcd",
+			ExpectedQuery:  "@test.status:fail",
+		},
+		{
+			Name:           "quoted",
+			Query:          `@git.branch:"my branch" service:foo`,
+			ExpectedBranch: "my branch",
+			ExpectedQuery:  "service:foo",
+		},
+		{
+			Name:          "or",
+			Query:         "@git.branch:main OR @git.branch:dev",
+			ExpectedQuery: "@git.branch:main OR @git.branch:dev",
+		},
+		{
+			Name:           "duplicate",
+			Query:          "@git.branch:main -@git.branch:dev @git.branch:other",
+			ExpectedBranch: "main",
+			ExpectedQuery:  "-@git.branch:dev @git.branch:other",
+		},
+		{
+			Name:          "not",
+			Query:         "NOT @git.branch:main env:ci",
+			ExpectedQuery: "NOT @git.branch:main env:ci",
+		},
+		{
+			Name:          "lowercase-operators",
+			Query:         "@git.branch:main or @git.branch:dev not @git.commit.sha:abcd",
+			ExpectedQuery: "@git.branch:main or @git.branch:dev not @git.commit.sha:abcd",
+		},
+		{
+			Name:          "dash",
+			Query:         "- @git.branch:main env:ci",
+			ExpectedQuery: "- @git.branch:main env:ci",
+		},
+		{
+			Name:          "and",
+			Query:         "env:ci AND @git.branch:main",
+			ExpectedQuery: "env:ci AND @git.branch:main",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			var branch, sha string
+			facets := []facetField{
+				{key: "@git.branch", field: &branch},
+				{key: "@git.commit.sha", field: &sha},
+			}
+
+			actual := extractFacets(facets, c.Query)
+			if actual != c.ExpectedQuery {
+				t.Errorf("Query doesn't match; got %v; want %v", actual, c.ExpectedQuery)
+			}
+			if branch != c.ExpectedBranch {
+				t.Errorf("Branch doesn't match; got %v; want %v", branch, c.ExpectedBranch)
+			}
+			if sha != c.ExpectedSHA {
+				t.Errorf("SHA doesn't match; got %v; want %v", sha, c.ExpectedSHA)
+			}
+
+			// Building the query from the facets should give back an equivalent query.
+			if rebuilt := buildFacetQuery(facets, actual); len(splitTerms(rebuilt)) != len(splitTerms(c.Query)) {
+				t.Errorf("Rebuilt query %v doesn't have the same terms as %v", rebuilt, c.Query)
+			}
+		})
+	}
+}
+
+func TestURLToLink_NegatedFacet(t *testing.T) {
+	// A negated term must stay in the query; extracting it into a field would drop the negation.
+	input := "https://acme.datadoghq.com/ci/test-runs?query=NOT%20%40git.branch%3Amain%20env%3Aci&from_ts=1736927929003&to_ts=1736949529003"
+	link, err := URLToLink(input)
+	if err != nil {
+		t.Fatalf("Failed to parse URL: %+v", err)
+	}
+	ci, ok := link.(*api.DatadogCI)
+	if !ok {
+		t.Fatalf("Expected a DatadogCI link but got %T", link)
+	}
+	if ci.Branch != "" || ci.Query != "NOT @git.branch:main env:ci" {
+		t.Errorf("Negated term was extracted; got branch %q and query %q", ci.Branch, ci.Query)
+	}
+
+	u, err := LinkToURL(ci)
+	if err != nil {
+		t.Fatalf("Failed to build URL: %+v", err)
+	}
+	parsed, err := url.Parse(u)
+	if err != nil {
+		t.Fatalf("Failed to parse URL %v: %+v", u, err)
+	}
+	if q := parsed.Query().Get("query"); q != "NOT @git.branch:main env:ci" {
+		t.Errorf("Query doesn't round trip; got %v", q)
+	}
+}
@@ -0,0 +1,248 @@
+package ddog
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/jlewi/ddctl/api"
+	"github.com/pkg/errors"
+)
+
+// TraceIDFormat is how a trace ID is written.
+type TraceIDFormat string
+
+const (
+	// TraceIDFormatAuto writes 128-bit IDs as hex and 64-bit IDs as decimal which is how Datadog displays them.
+	TraceIDFormatAuto TraceIDFormat = "auto"
+	// TraceIDFormatHex writes all 128 bits as 32 lowercase hex characters, the OpenTelemetry trace_id format.
+	TraceIDFormatHex TraceIDFormat = "hex"
+	// TraceIDFormatDecimal writes the lower 64 bits in decimal, the format of 64-bit Datadog trace IDs.
+	TraceIDFormatDecimal TraceIDFormat = "decimal"
+	// TraceIDFormatLower64 writes the lower 64 bits as 16 lowercase hex characters.
+	TraceIDFormatLower64 TraceIDFormat = "lower64"
+
+	// otelTraceIDLength and otelSpanIDLength are the number of hex characters in OpenTelemetry IDs.
+	otelTraceIDLength = 32
+	otelSpanIDLength  = 16
+)
+
+var (
+	// TraceIDFormats are the supported trace ID formats.
+	TraceIDFormats = []TraceIDFormat{TraceIDFormatAuto, TraceIDFormatHex, TraceIDFormatDecimal, TraceIDFormatLower64}
+)
+
+// TraceID is a 128-bit trace ID. 64-bit trace IDs have a zero High.
+type TraceID struct {
+	High uint64
+	Low  uint64
+}
+
+// Hex returns the trace ID as 32 lowercase hex characters.
+func (t TraceID) Hex() string {
+	return fmt.Sprintf("%016x%016x", t.High, t.Low)
+}
+
+// Decimal returns the lower 64 bits of the trace ID in decimal.
+func (t TraceID) Decimal() string {
+	return strconv.FormatUint(t.Low, 10)
+}
+
+// Lower64 returns the lower 64 bits of the trace ID as 16 lowercase hex characters.
+func (t TraceID) Lower64() string {
+	return fmt.Sprintf("%016x", t.Low)
+}
+
+// Format returns the trace ID in the given format.
+func (t TraceID) Format(format TraceIDFormat) (string, error) {
+	switch format {
+	case TraceIDFormatAuto, "":
+		if t.High != 0 {
+			return t.Hex(), nil
+		}
+		return t.Decimal(), nil
+	case TraceIDFormatHex:
+		return t.Hex(), nil
+	case TraceIDFormatDecimal:
+		return t.Decimal(), nil
+	case TraceIDFormatLower64:
+		return t.Lower64(), nil
+	default:
+		return "", errors.Errorf("Unsupported trace ID format %v; must be one of %v", format, TraceIDFormats)
+	}
+}
+
+// ParseTraceID parses a trace ID written as hex or decimal.
+//
+// Values with a 0x prefix or containing the letters a-f are hex, e.g. an OpenTelemetry trace_id. Other values are
+// decimal, e.g. a 64-bit Datadog trace ID, except for 32 digits which are too long for a 64-bit decimal ID so they
+// are hex. Any other value consisting only of digits that doesn't fit in 64 bits returns an error.
+func ParseTraceID(value string) (TraceID, error) {
+	v, isHex := trimHexPrefix(value)
+	if len(v) == otelTraceIDLength {
+		isHex = true
+	}
+	if v == "" {
+		return TraceID{}, errors.New("Trace ID is empty")
+	}
+
+	n, ok := new(big.Int).SetString(v, base(isHex))
+	if !ok || n.Sign() < 0 {
+		return TraceID{}, errors.Errorf("Trace ID %v isn't a hex or decimal number", value)
+	}
+	if !isHex && n.BitLen() > 64 {
+		return TraceID{}, errors.Errorf("Trace ID %v is too long for a 64-bit decimal ID; prefix it with 0x if it is hex", value)
+	}
+	if n.BitLen() > 128 {
+		return TraceID{}, errors.Errorf("Trace ID %v is longer than 128 bits", value)
+	}
+
+	low := new(big.Int).And(n, new(big.Int).SetUint64(^uint64(0)))
+	high := new(big.Int).Rsh(n, 64)
+	return TraceID{High: high.Uint64(), Low: low.Uint64()}, nil
+}
+
+// ParseOTelTraceID parses an OpenTelemetry trace ID; 32 hex characters that aren't all zero.
+func ParseOTelTraceID(value string) (TraceID, error) {
+	v := strings.TrimSpace(value)
+	if len(v) != otelTraceIDLength || !isHexString(v) {
+		return TraceID{}, errors.Errorf("OpenTelemetry trace ID %v must be %v hex characters", value, otelTraceIDLength)
+	}
+	id, err := ParseTraceID("0x" + v)
+	if err != nil {
+		return TraceID{}, err
+	}
+	if id == (TraceID{}) {
+		return TraceID{}, errors.Errorf("OpenTelemetry trace ID %v is all zeros which isn't a valid trace ID", value)
+	}
+	return id, nil
+}
+
+// ParseSpanID parses a 64-bit span ID written as hex or decimal and returns it in decimal, the format Datadog uses
+// for span IDs.
+//
+// Values with a 0x prefix or containing the letters a-f are hex, e.g. an OpenTelemetry span_id. Other values are
+// decimal.
+func ParseSpanID(value string) (string, error) {
+	v, isHex := trimHexPrefix(value)
+	if v == "" {
+		return "", errors.New("Span ID is empty")
+	}
+
+	n, err := strconv.ParseUint(v, base(isHex), 64)
+	if err != nil {
+		return "", errors.Wrapf(err, "Span ID %v isn't a 64-bit hex or decimal number", value)
+	}
+	return strconv.FormatUint(n, 10), nil
+}
+
+// trimHexPrefix trims whitespace and any 0x prefix from an ID. It returns true if the ID is hex; either because
+// it had the prefix or because it contains letters. IDs consisting only of digits are decimal.
+func trimHexPrefix(value string) (string, bool) {
+	v := strings.TrimSpace(value)
+	if strings.HasPrefix(v, "0x") || strings.HasPrefix(v, "0X") {
+		return v[2:], true
+	}
+	return v, !isDigits(v)
+}
+
+// Traceparent is a parsed W3C traceparent header.
+type Traceparent struct {
+	TraceID TraceID
+	// SpanID is the parent-id of the header in decimal
+	SpanID  string
+	Sampled bool
+}
+
+// ParseTraceparent parses a W3C traceparent header e.g. 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01.
+// See https://www.w3.org/TR/trace-context/#traceparent-header.
+func ParseTraceparent(header string) (*Traceparent, error) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 {
+		return nil, errors.Errorf("traceparent %v must have the form version-traceid-parentid-flags", header)
+	}
+
+	version, traceID, spanID, flags := parts[0], parts[1], parts[2], parts[3]
+	if len(version) != 2 || !isHexString(version) || strings.EqualFold(version, "ff") {
+		return nil, errors.Errorf("traceparent %v has an invalid version %v", header, version)
+	}
+	// Future versions may append fields but version 00 has exactly four
+	if version == "00" && len(parts) != 4 {
+		return nil, errors.Errorf("traceparent %v has more than four fields", header)
+	}
+	if len(flags) != 2 || !isHexString(flags) {
+		return nil, errors.Errorf("traceparent %v has invalid flags %v", header, flags)
+	}
+
+	id, err := ParseOTelTraceID(traceID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "traceparent %v has an invalid trace ID", header)
+	}
+
+	if len(spanID) != otelSpanIDLength || !isHexString(spanID) || strings.Trim(spanID, "0") == "" {
+		return nil, errors.Errorf("traceparent %v has an invalid parent ID %v; it must be %v hex characters that aren't all zero", header, spanID, otelSpanIDLength)
+	}
+	span, err := ParseSpanID("0x" + spanID)
+	if err != nil {
+		return nil, err
+	}
+
+	flagBits, err := hex.DecodeString(flags)
+	if err != nil {
+		return nil, errors.Wrapf(err, "traceparent %v has invalid flags %v", header, flags)
+	}
+
+	return &Traceparent{
+		TraceID: id,
+		SpanID:  span,
+		Sampled: flagBits[0]&0x01 != 0,
+	}, nil
+}
+
+// NormalizeTraceIDs rewrites the TraceID and SpanID of the link in the formats Datadog uses in trace URLs. The
+// trace ID is written in the given format and the span ID in decimal. See ParseTraceID and ParseSpanID for the
+// formats that are accepted.
+func NormalizeTraceIDs(link *api.DatadogTrace, format TraceIDFormat) error {
+	if link.TraceID != "" {
+		id, err := ParseTraceID(link.TraceID)
+		if err != nil {
+			return err
+		}
+		formatted, err := id.Format(format)
+		if err != nil {
+			return err
+		}
+		link.TraceID = formatted
+	}
+	if link.SpanID != "" {
+		span, err := ParseSpanID(link.SpanID)
+		if err != nil {
+			return err
+		}
+		link.SpanID = span
+	}
+	return nil
+}
+
+func base(isHex bool) int {
+	if isHex {
+		return 16
+	}
+	return 10
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func isHexString(s string) bool {
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
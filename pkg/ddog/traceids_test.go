@@ -0,0 +1,225 @@
+package ddog
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jlewi/ddctl/api"
+)
+
+func TestParseTraceID(t *testing.T) {
+	type testCase struct {
+		name     string
+		input    string
+		expected TraceID
+	}
+
+	cases := []testCase{
+		{
+			name:     "otel",
+			input:    "4bf92f3577b34da6a3ce929d0e0e4736",
+			expected: TraceID{High: 0x4bf92f3577b34da6, Low: 0xa3ce929d0e0e4736},
+		},
+		{
+			name:     "otel-64-bit",
+			input:    "0000000000000000a3ce929d0e0e4736",
+			expected: TraceID{Low: 0xa3ce929d0e0e4736},
+		},
+		{
+			name:     "decimal",
+			input:    "11803532876627986230",
+			expected: TraceID{Low: 11803532876627986230},
+		},
+		{
+			name:     "otel-digits",
+			input:    "00000000000000001234567890123456",
+			expected: TraceID{Low: 0x1234567890123456},
+		},
+		{
+			name:     "decimal-16-digits",
+			input:    "1234567890123456",
+			expected: TraceID{Low: 1234567890123456},
+		},
+		{
+			name:     "hex-prefix",
+			input:    "0x1234",
+			expected: TraceID{Low: 0x1234},
+		},
+		{
+			name:     "hex-letters",
+			input:    " a3ce929d0e0e4736 ",
+			expected: TraceID{Low: 0xa3ce929d0e0e4736},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			actual, err := ParseTraceID(c.input)
+			if err != nil {
+				t.Fatalf("Failed to parse trace ID: %+v", err)
+			}
+			if d := cmp.Diff(c.expected, actual); d != "" {
+				t.Errorf("Unexpected trace ID:\n%v", d)
+			}
+		})
+	}
+
+	for _, invalid := range []string{"", "xyz", "4bf92f3577b34da6a3ce929d0e0e47361", "123456789012345678901234"} {
+		if _, err := ParseTraceID(invalid); err == nil {
+			t.Errorf("Expected an error parsing %q", invalid)
+		}
+	}
+}
+
+func TestTraceIDFormat(t *testing.T) {
+	id := TraceID{High: 0x4bf92f3577b34da6, Low: 0xa3ce929d0e0e4736}
+	expected := map[TraceIDFormat]string{
+		TraceIDFormatAuto:    "4bf92f3577b34da6a3ce929d0e0e4736",
+		TraceIDFormatHex:     "4bf92f3577b34da6a3ce929d0e0e4736",
+		TraceIDFormatDecimal: "11803532876627986230",
+		TraceIDFormatLower64: "a3ce929d0e0e4736",
+	}
+	for format, e := range expected {
+		actual, err := id.Format(format)
+		if err != nil {
+			t.Fatalf("Failed to format trace ID as %v: %+v", format, err)
+		}
+		if actual != e {
+			t.Errorf("Format %v: got %v; want %v", format, actual, e)
+		}
+	}
+
+	auto, err := TraceID{Low: 42}.Format(TraceIDFormatAuto)
+	if err != nil {
+		t.Fatalf("Failed to format trace ID: %+v", err)
+	}
+	if auto != "42" {
+		t.Errorf("64-bit trace IDs should be decimal; got %v", auto)
+	}
+}
+
+func TestParseSpanID(t *testing.T) {
+	cases := map[string]string{
+		"00f067aa0ba902b7":    "67667974448284343",
+		"0xf067aa0ba902b7":    "67667974448284343",
+		"2754376459340700567": "2754376459340700567",
+		// Values consisting only of digits are decimal even if they have as many characters as a hex span ID
+		"1234567890123456": "1234567890123456",
+	}
+	for input, expected := range cases {
+		actual, err := ParseSpanID(input)
+		if err != nil {
+			t.Fatalf("Failed to parse span ID %v: %+v", input, err)
+		}
+		if actual != expected {
+			t.Errorf("Span ID %v: got %v; want %v", input, actual, expected)
+		}
+	}
+
+	if _, err := ParseSpanID("4bf92f3577b34da6a3ce929d0e0e4736"); err == nil {
+		t.Errorf("Expected an error parsing a span ID longer than 64 bits")
+	}
+}
+
+func TestParseTraceparent(t *testing.T) {
+	type testCase struct {
+		name     string
+		input    string
+		expected *Traceparent
+	}
+
+	cases := []testCase{
+		{
+			name:  "sampled",
+			input: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			expected: &Traceparent{
+				TraceID: TraceID{High: 0x4bf92f3577b34da6, Low: 0xa3ce929d0e0e4736},
+				SpanID:  "67667974448284343",
+				Sampled: true,
+			},
+		},
+		{
+			name:  "future-version",
+			input: "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-extra",
+			expected: &Traceparent{
+				TraceID: TraceID{High: 0x4bf92f3577b34da6, Low: 0xa3ce929d0e0e4736},
+				SpanID:  "67667974448284343",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			actual, err := ParseTraceparent(c.input)
+			if err != nil {
+				t.Fatalf("Failed to parse traceparent: %+v", err)
+			}
+			if d := cmp.Diff(c.expected, actual); d != "" {
+				t.Errorf("Unexpected traceparent:\n%v", d)
+			}
+		})
+	}
+
+	invalid := []string{
+		"4bf92f3577b34da6a3ce929d0e0e4736",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+	}
+	for _, i := range invalid {
+		if _, err := ParseTraceparent(i); err == nil {
+			t.Errorf("Expected an error parsing %v", i)
+		}
+	}
+}
+
+func TestNormalizeTraceIDs(t *testing.T) {
+	link := &api.DatadogTrace{
+		TraceID: "0000000000000000a3ce929d0e0e4736",
+		SpanID:  "00f067aa0ba902b7",
+	}
+	if err := NormalizeTraceIDs(link, TraceIDFormatAuto); err != nil {
+		t.Fatalf("Failed to normalize trace IDs: %+v", err)
+	}
+
+	expected := &api.DatadogTrace{
+		TraceID: "11803532876627986230",
+		SpanID:  "67667974448284343",
+	}
+	if d := cmp.Diff(expected, link); d != "" {
+		t.Errorf("Unexpected link:\n%v", d)
+	}
+}
+
+func TestBuildTraceURL_OTelIDs(t *testing.T) {
+	link := &api.DatadogTrace{
+		BaseURL: "https://acme.datadoghq.com",
+		TraceID: "4BF92F3577B34DA6A3CE929D0E0E4736",
+		SpanID:  "00f067aa0ba902b7",
+	}
+	u, err := BuildTraceURL(link)
+	if err != nil {
+		t.Fatalf("Failed to build URL: %+v", err)
+	}
+
+	expected := "https://acme.datadoghq.com/apm/trace/4bf92f3577b34da6a3ce929d0e0e4736?shouldShowLegend=false&spanID=67667974448284343"
+	if u != expected {
+		t.Errorf("URL doesn't match; got %v; want %v", u, expected)
+	}
+	// A 64-bit ID written as 128 bits stays hex
+	link.TraceID = "0000000000000000a3ce929d0e0e4736"
+	u, err = BuildTraceURL(link)
+	if err != nil {
+		t.Fatalf("Failed to build URL: %+v", err)
+	}
+	expected = "https://acme.datadoghq.com/apm/trace/0000000000000000a3ce929d0e0e4736?shouldShowLegend=false&spanID=67667974448284343"
+	if u != expected {
+		t.Errorf("URL doesn't match; got %v; want %v", u, expected)
+	}
+
+	if link.SpanID != "00f067aa0ba902b7" {
+		t.Errorf("BuildTraceURL shouldn't modify the link; got span ID %v", link.SpanID)
+	}
+}
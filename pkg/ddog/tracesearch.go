@@ -0,0 +1,58 @@
+package ddog
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/jlewi/ddctl/api"
+)
+
+const (
+	traceSearchPath = "/apm/traces"
+)
+
+// BuildTraceSearchURL builds the URL for a Trace Explorer link.
+func BuildTraceSearchURL(link *api.DatadogTraceSearch) (string, error) {
+	queryParams := url.Values{}
+	addString(queryParams, "query", link.Query)
+	addString(queryParams, "viz", link.VisualizeAs)
+	addString(queryParams, "agg_m", link.Measure)
+	addString(queryParams, "agg_t", link.AggType)
+	addString(queryParams, "agg_q", strings.Join(link.GroupBy, ","))
+	if link.TopN != 0 {
+		addString(queryParams, "top_n", strconv.Itoa(link.TopN))
+	}
+	if err := addTimeRange(queryParams, link.FromTS, link.ToTS); err != nil {
+		return "", err
+	}
+	addExtraParams(queryParams, link.ExtraParams)
+
+	encodedQuery := queryParams.Encode()
+	u := fmt.Sprintf("%s%s?%s", link.BaseURL, traceSearchPath, encodedQuery)
+	return u, nil
+}
+
+// TraceSearchURLToLink converts a Trace Explorer URL to a DatadogTraceSearch link.
+func TraceSearchURLToLink(u url.URL) (*api.DatadogTraceSearch, error) {
+	link := &api.DatadogTraceSearch{
+		APIVersion: api.TraceSearchGVK.GroupVersion().String(),
+		Kind:       api.TraceSearchGVK.Kind,
+		BaseURL:    getBaseURL(u),
+	}
+
+	queryParamMap := map[string]queryValHandler{
+		"query":   bindToString(&link.Query),
+		"viz":     bindToString(&link.VisualizeAs),
+		"agg_m":   bindToString(&link.Measure),
+		"agg_t":   bindToString(&link.AggType),
+		"agg_q":   bindToStringSlice(&link.GroupBy),
+		"top_n":   bindToInt(&link.TopN),
+		"from_ts": bindToString(&link.FromTS),
+		"to_ts":   bindToString(&link.ToTS),
+	}
+
+	link.ExtraParams = bindQueryParams(u.Query(), queryParamMap)
+	return link, nil
+}
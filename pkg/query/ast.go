@@ -0,0 +1,149 @@
+// Package query parses Datadog log search queries into an AST and prints them back as strings.
+//
+// The syntax is described in https://docs.datadoghq.com/logs/explorer/search_syntax/
+package query
+
+import (
+	"strings"
+)
+
+// Node is a node in the AST of a query.
+type Node interface {
+	// Pos is the byte offset in the query of the first character of the node.
+	Pos() int
+	// End is the byte offset in the query immediately after the last character of the node.
+	End() int
+	// String returns the canonical string for the node.
+	String() string
+}
+
+// Span is the location of a node in the query it was parsed from.
+// Nodes that weren't created by the parser have a zero Span.
+type Span struct {
+	Start int
+	Stop  int
+}
+
+func (s Span) Pos() int {
+	return s.Start
+}
+
+func (s Span) End() int {
+	return s.Stop
+}
+
+// Text is free text (e.g. RequestLoggingMiddleware) or the value of a Field.
+type Text struct {
+	Span
+	// Value is the text. If Quoted is false it is the text as written including any escape sequences and wildcards.
+	// If Quoted is true it is the phrase between the quotes with the escape sequences removed.
+	Value string
+	// Quoted is true if the text is a quoted phrase e.g. "hello world"
+	Quoted bool
+}
+
+// HasWildcard returns true if the text contains an unescaped wildcard (* or ?).
+// Wildcards in quoted phrases match literally.
+func (t *Text) HasWildcard() bool {
+	if t.Quoted {
+		return false
+	}
+	escaped := false
+	for _, r := range t.Value {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '*' || r == '?':
+			return true
+		}
+	}
+	return false
+}
+
+// Field matches a tag (e.g. env:prod) or a facet (e.g. @http.status_code:500).
+type Field struct {
+	Span
+	// Key is the tag or facet including the @ prefix for facets.
+	Key string
+	// Value is one of *Text, *Range, *Comparison or *Group. A Group contains the boolean combination
+	// of values e.g. env:(prod OR staging)
+	Value Node
+}
+
+// IsFacet returns true if the field is a facet, i.e. an attribute prefixed with @.
+func (f *Field) IsFacet() bool {
+	return strings.HasPrefix(f.Key, "@")
+}
+
+// Range is a numeric or lexicographic range e.g. [100 TO 200].
+type Range struct {
+	Span
+	Low  string
+	High string
+	// ExclusiveLow and ExclusiveHigh are true if the bound is written with a curly brace e.g. {100 TO 200}
+	ExclusiveLow  bool
+	ExclusiveHigh bool
+}
+
+// Comparison compares a numeric value e.g. >=500.
+type Comparison struct {
+	Span
+	// Op is one of >, >=, < or <=
+	Op    string
+	Value string
+}
+
+// Not negates its operand. It is written either as -operand or NOT operand.
+type Not struct {
+	Span
+	Operand Node
+	// Keyword is true if the negation was written with the NOT keyword rather than -.
+	Keyword bool
+}
+
+// And matches if all of its operands match.
+type And struct {
+	Span
+	Operands []Node
+	// Implicit is true if the operands are separated by whitespace rather than the AND keyword.
+	Implicit bool
+}
+
+// Or matches if any of its operands match.
+type Or struct {
+	Span
+	Operands []Node
+}
+
+// Group is an expression in parentheses.
+type Group struct {
+	Span
+	Expr Node
+}
+
+// Walk traverses the AST in depth first order calling fn for each node.
+// If fn returns false the children of the node aren't visited.
+func Walk(n Node, fn func(Node) bool) {
+	if n == nil || !fn(n) {
+		return
+	}
+
+	switch v := n.(type) {
+	case *Field:
+		Walk(v.Value, fn)
+	case *Not:
+		Walk(v.Operand, fn)
+	case *And:
+		for _, o := range v.Operands {
+			Walk(o, fn)
+		}
+	case *Or:
+		for _, o := range v.Operands {
+			Walk(o, fn)
+		}
+	case *Group:
+		Walk(v.Expr, fn)
+	}
+}
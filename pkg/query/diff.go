@@ -0,0 +1,61 @@
+package query
+
+// Clauses returns the canonical form of the clauses ANDed together in the query e.g. "env:prod -status:info"
+// has the clauses env:prod and -status:info. Queries that match the same logs because they only differ in
+// formatting or in the order of their clauses have the same clauses.
+func Clauses(q string) ([]string, error) {
+	n, err := Parse(q)
+	if err != nil {
+		return nil, err
+	}
+	if n == nil {
+		return nil, nil
+	}
+
+	terms := conjuncts(Normalize(n))
+	clauses := make([]string, 0, len(terms))
+	for _, t := range terms {
+		clauses = append(clauses, Print(t))
+	}
+	return clauses, nil
+}
+
+// DiffClauses returns the clauses of query b that aren't in query a and the clauses of a that aren't in b.
+// See Clauses.
+func DiffClauses(a string, b string) ([]string, []string, error) {
+	clausesA, err := Clauses(a)
+	if err != nil {
+		return nil, nil, err
+	}
+	clausesB, err := Clauses(b)
+	if err != nil {
+		return nil, nil, err
+	}
+	added, removed := DiffStrings(clausesA, clausesB)
+	return added, removed, nil
+}
+
+// DiffStrings returns the elements of b that aren't in a and the elements of a that aren't in b.
+func DiffStrings(a []string, b []string) ([]string, []string) {
+	inA := map[string]bool{}
+	for _, s := range a {
+		inA[s] = true
+	}
+	inB := map[string]bool{}
+	for _, s := range b {
+		inB[s] = true
+	}
+
+	var added, removed []string
+	for _, s := range b {
+		if !inA[s] {
+			added = append(added, s)
+		}
+	}
+	for _, s := range a {
+		if !inB[s] {
+			removed = append(removed, s)
+		}
+	}
+	return added, removed
+}
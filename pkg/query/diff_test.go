@@ -0,0 +1,59 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDiffClauses(t *testing.T) {
+	type testCase struct {
+		Name            string
+		A               string
+		B               string
+		ExpectedAdded   []string
+		ExpectedRemoved []string
+	}
+
+	cases := []testCase{
+		{
+			Name: "formatting-only",
+			A:    `service:foo AND env:prod NOT "hello"`,
+			B:    `env:prod   -hello service:foo`,
+		},
+		{
+			Name:            "changed-clauses",
+			A:               "service:foo env:staging (a OR b)",
+			B:               "service:foo env:prod (a OR b OR c) @http.status_code:>=500",
+			ExpectedAdded:   []string{"env:prod", "@http.status_code:>=500", "a OR b OR c"},
+			ExpectedRemoved: []string{"env:staging", "a OR b"},
+		},
+		{
+			Name:          "parenthesized",
+			A:             "(env:prod service:web)",
+			B:             "env:prod service:web status:error",
+			ExpectedAdded: []string{"status:error"},
+		},
+		{
+			Name:          "empty",
+			A:             "",
+			B:             "status:error",
+			ExpectedAdded: []string{"status:error"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			added, removed, err := DiffClauses(c.A, c.B)
+			if err != nil {
+				t.Fatalf("Failed to diff queries: %v", err)
+			}
+			if d := cmp.Diff(c.ExpectedAdded, added); d != "" {
+				t.Errorf("Added clauses don't match; diff\n%v", d)
+			}
+			if d := cmp.Diff(c.ExpectedRemoved, removed); d != "" {
+				t.Errorf("Removed clauses don't match; diff\n%v", d)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package query
+
+import (
+	"github.com/pkg/errors"
+)
+
+// AddTerm returns the query ANDed with term. The query is returned unchanged if it already requires the term.
+func AddTerm(n Node, term Node) Node {
+	terms := conjuncts(n)
+	for _, t := range terms {
+		if equal(t, term) {
+			return n
+		}
+	}
+	return rebuildAnd(n, append(terms, term))
+}
+
+// RemoveTerm removes a term that the query requires. Only terms that are ANDed with the rest of the query are
+// removed; terms inside an OR or a negation are left alone so the meaning of the rest of the query doesn't change.
+// To remove a negated term pass the negation e.g. -env:prod. A value can also be removed from a field with a
+// group of values e.g. removing env:prod from env:(prod OR staging) leaves env:staging.
+// An error is returned if the query doesn't contain the term.
+func RemoveTerm(n Node, term Node) (Node, error) {
+	terms := conjuncts(n)
+	kept := make([]Node, 0, len(terms))
+	found := false
+	for _, t := range terms {
+		if equal(t, term) {
+			found = true
+			continue
+		}
+		if remaining, ok := removeFieldValue(t, term); ok {
+			found = true
+			t = remaining
+		}
+		kept = append(kept, t)
+	}
+
+	if !found {
+		return nil, errors.Errorf("%v isn't required by the query %v", Print(term), Print(n))
+	}
+	return rebuildAnd(n, kept), nil
+}
+
+// ReplaceTerm replaces the value of the fields with the same key as term, e.g. replacing @http.method:GET with
+// @http.method:POST. Negated fields are kept. The term is added if the query doesn't have the field.
+func ReplaceTerm(n Node, term Node) (Node, error) {
+	field, ok := term.(*Field)
+	if !ok {
+		return nil, errors.Errorf("%v isn't a field; only fields e.g. env:prod can be replaced", Print(term))
+	}
+
+	terms := conjuncts(n)
+	replaced := make([]Node, 0, len(terms))
+	found := false
+	for _, t := range terms {
+		if f, ok := t.(*Field); ok && f.Key == field.Key {
+			if !found {
+				replaced = append(replaced, field)
+				found = true
+			}
+			continue
+		}
+		replaced = append(replaced, t)
+	}
+
+	if !found {
+		replaced = append(replaced, field)
+	}
+	return rebuildAnd(n, replaced), nil
+}
+
+// conjuncts returns the terms ANDed together in the query. Parentheses around the whole query are ignored.
+func conjuncts(n Node) []Node {
+	switch v := unwrapGroup(n).(type) {
+	case nil:
+		return nil
+	case *And:
+		return append([]Node{}, v.Operands...)
+	}
+	return []Node{n}
+}
+
+// unwrapGroup returns the expression inside any parentheses around n.
+func unwrapGroup(n Node) Node {
+	for {
+		g, ok := n.(*Group)
+		if !ok {
+			return n
+		}
+		n = g.Expr
+	}
+}
+
+// rebuildAnd returns the AND of the terms preserving how the original query wrote AND.
+func rebuildAnd(original Node, terms []Node) Node {
+	switch len(terms) {
+	case 0:
+		return nil
+	case 1:
+		return terms[0]
+	}
+
+	implicit := true
+	if a, ok := unwrapGroup(original).(*And); ok {
+		implicit = a.Implicit
+	}
+	return &And{Operands: terms, Implicit: implicit}
+}
+
+// removeFieldValue removes the value of term from a field matching any of a group of values.
+// It returns false if t isn't such a field or doesn't contain the value.
+func removeFieldValue(t Node, term Node) (Node, bool) {
+	f, ok := t.(*Field)
+	if !ok {
+		return nil, false
+	}
+	remove, ok := term.(*Field)
+	if !ok || remove.Key != f.Key {
+		return nil, false
+	}
+	g, ok := f.Value.(*Group)
+	if !ok {
+		return nil, false
+	}
+	or, ok := g.Expr.(*Or)
+	if !ok {
+		return nil, false
+	}
+
+	values := make([]Node, 0, len(or.Operands))
+	for _, v := range or.Operands {
+		if !equal(v, remove.Value) {
+			values = append(values, v)
+		}
+	}
+
+	switch len(values) {
+	case len(or.Operands):
+		return nil, false
+	case 1:
+		return &Field{Key: f.Key, Value: values[0]}, true
+	}
+	return &Field{Key: f.Key, Value: &Group{Expr: &Or{Operands: values}}}, true
+}
+
+// equal returns true if the nodes have the same canonical form.
+func equal(a Node, b Node) bool {
+	return canonical(a) == canonical(b)
+}
+
+// canonical returns the canonical string of the node without modifying it.
+func canonical(n Node) string {
+	s := Print(n)
+	formatted, err := Format(s)
+	if err != nil {
+		return s
+	}
+	return formatted
+}
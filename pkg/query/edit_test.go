@@ -0,0 +1,118 @@
+package query
+
+import (
+	"testing"
+)
+
+func TestEdit(t *testing.T) {
+	type testCase struct {
+		Name     string
+		Input    string
+		Edit     func(n Node, term Node) (Node, error)
+		Term     string
+		Expected string
+	}
+
+	add := func(n Node, term Node) (Node, error) {
+		return AddTerm(n, term), nil
+	}
+
+	cases := []testCase{
+		{
+			Name:     "add",
+			Input:    "service:web -status:info",
+			Edit:     add,
+			Term:     "env:staging",
+			Expected: "service:web -status:info env:staging",
+		},
+		{
+			Name:     "add-to-or",
+			Input:    "service:web OR service:api",
+			Edit:     add,
+			Term:     "env:staging",
+			Expected: "(service:web OR service:api) env:staging",
+		},
+		{
+			Name:     "add-existing",
+			Input:    `env:"staging" service:web`,
+			Edit:     add,
+			Term:     "env:staging",
+			Expected: `env:"staging" service:web`,
+		},
+		{
+			Name:     "add-to-empty",
+			Input:    "",
+			Edit:     add,
+			Term:     "env:staging",
+			Expected: "env:staging",
+		},
+		{
+			Name:     "remove",
+			Input:    "env:prod AND service:feserver* AND -service:feserver*",
+			Edit:     RemoveTerm,
+			Term:     "service:feserver*",
+			Expected: "env:prod AND -service:feserver*",
+		},
+		{
+			Name:     "remove-from-group",
+			Input:    "(env:prod service:web)",
+			Edit:     RemoveTerm,
+			Term:     "env:prod",
+			Expected: "service:web",
+		},
+		{
+			Name:     "add-to-group",
+			Input:    "((env:prod AND service:web))",
+			Edit:     add,
+			Term:     "service:web",
+			Expected: "((env:prod AND service:web))",
+		},
+		{
+			Name:     "remove-negation",
+			Input:    "env:prod -service:feserver* (service:feserver* OR a)",
+			Edit:     RemoveTerm,
+			Term:     "-service:feserver*",
+			Expected: "env:prod (service:feserver* OR a)",
+		},
+		{
+			Name:     "remove-group-value",
+			Input:    "env:(prod OR staging OR dev) service:web",
+			Edit:     RemoveTerm,
+			Term:     "env:staging",
+			Expected: "env:(prod OR dev) service:web",
+		},
+		{
+			Name:     "replace",
+			Input:    "service:web @http.method:GET -@http.method:HEAD",
+			Edit:     ReplaceTerm,
+			Term:     "@http.method:POST",
+			Expected: "service:web @http.method:POST -@http.method:HEAD",
+		},
+		{
+			Name:     "replace-missing",
+			Input:    "service:web",
+			Edit:     ReplaceTerm,
+			Term:     "@http.method:(POST OR PUT)",
+			Expected: "service:web @http.method:(POST OR PUT)",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			actual, err := c.Edit(MustParse(c.Input), MustParse(c.Term))
+			if err != nil {
+				t.Fatalf("Failed to edit query: %v", err)
+			}
+			if Print(actual) != c.Expected {
+				t.Errorf("Got %v;\n Want %v", Print(actual), c.Expected)
+			}
+		})
+	}
+}
+
+func TestRemoveTermMissing(t *testing.T) {
+	_, err := RemoveTerm(MustParse("env:prod (service:web OR service:api)"), MustParse("service:web"))
+	if err == nil {
+		t.Errorf("Expected an error removing a term inside an OR")
+	}
+}
@@ -0,0 +1,164 @@
+package query
+
+import (
+	"sort"
+	"strings"
+)
+
+const (
+	// specialChars are the characters that have a meaning in the search syntax and therefore require a value
+	// containing them to be quoted.
+	specialChars = " \t\n\r()[]{}\"\\:*?<>=!^~/+&|"
+)
+
+// Format parses the query and returns its canonical form. Formatting doesn't change which logs the query matches.
+// See Normalize for the rules.
+func Format(q string) (string, error) {
+	n, err := Parse(q)
+	if err != nil {
+		return "", err
+	}
+	return Print(Normalize(n)), nil
+}
+
+// Normalize rewrites the AST into a canonical form
+//   - AND is implicit and NOT is written as -
+//   - redundant parentheses are removed and nested ANDs and ORs are flattened
+//   - values are only quoted when they need to be
+//   - the operands of an AND are ordered; free text first, followed by tags and then facets sorted by key.
+//     Sorting is stable so the relative order of terms with the same key is preserved.
+//
+// Normalize modifies the nodes in place and returns the new root. The spans of the nodes are no longer meaningful.
+func Normalize(n Node) Node {
+	switch v := n.(type) {
+	case *Text:
+		if v.Quoted && !needsQuotes(v.Value) {
+			v.Quoted = false
+		}
+		return v
+	case *Field:
+		v.Value = normalizeFieldValue(v.Value)
+		return v
+	case *Not:
+		v.Keyword = false
+		v.Operand = Normalize(v.Operand)
+		if inner, ok := v.Operand.(*Not); ok {
+			// A double negation cancels out
+			return inner.Operand
+		}
+		return v
+	case *Group:
+		// The printer adds parentheses where they are needed
+		return Normalize(v.Expr)
+	case *And:
+		operands := make([]Node, 0, len(v.Operands))
+		for _, o := range v.Operands {
+			o = Normalize(o)
+			if inner, ok := o.(*And); ok {
+				operands = append(operands, inner.Operands...)
+				continue
+			}
+			operands = append(operands, o)
+		}
+		sort.SliceStable(operands, func(i, j int) bool {
+			ci, ki := sortKey(operands[i])
+			cj, kj := sortKey(operands[j])
+			if ci != cj {
+				return ci < cj
+			}
+			return ki < kj
+		})
+		v.Operands = operands
+		v.Implicit = true
+		return v
+	case *Or:
+		operands := make([]Node, 0, len(v.Operands))
+		for _, o := range v.Operands {
+			o = Normalize(o)
+			if inner, ok := o.(*Or); ok {
+				operands = append(operands, inner.Operands...)
+				continue
+			}
+			operands = append(operands, o)
+		}
+		v.Operands = operands
+		return v
+	}
+	return n
+}
+
+// normalizeFieldValue normalizes the value of a field. Unlike other expressions, a group of values must keep its
+// parentheses unless it contains a single value because key:a OR b means (key:a) OR b.
+func normalizeFieldValue(n Node) Node {
+	g, ok := n.(*Group)
+	if !ok {
+		return Normalize(n)
+	}
+
+	expr := Normalize(g.Expr)
+	if t, ok := expr.(*Text); ok {
+		return t
+	}
+	g.Expr = expr
+	return g
+}
+
+// Term categories used to order the operands of an AND.
+const (
+	categoryText = iota
+	categoryTag
+	categoryFacet
+	categoryOther
+)
+
+// sortKey returns the category and key used to order the operand of an AND. A negated field is ordered
+// with the field.
+func sortKey(n Node) (int, string) {
+	if not, ok := n.(*Not); ok {
+		n = not.Operand
+	}
+
+	switch v := n.(type) {
+	case *Text:
+		return categoryText, ""
+	case *Field:
+		if v.IsFacet() {
+			return categoryFacet, v.Key
+		}
+		return categoryTag, v.Key
+	}
+	return categoryOther, ""
+}
+
+// needsQuotes returns true if a value must be quoted to be interpreted as a single literal value.
+func needsQuotes(value string) bool {
+	if value == "" || strings.HasPrefix(value, "-") || strings.ContainsAny(value, specialChars) {
+		return true
+	}
+
+	// Quote operator words in any case so "or" isn't mistaken for an operator or flagged by Lint.
+	switch strings.ToUpper(value) {
+	case keywordAnd, keywordOr, keywordNot:
+		return true
+	}
+	return value == keywordTo
+}
+
+// Literal returns the Text matching value exactly. The value is quoted if it contains whitespace, wildcards or
+// other characters with a special meaning.
+func Literal(value string) *Text {
+	return &Text{Value: value, Quoted: needsQuotes(value)}
+}
+
+// Wildcard returns the Text for a pattern in which * and ? are wildcards. Any other special characters
+// are escaped with a backslash.
+func Wildcard(pattern string) *Text {
+	var sb strings.Builder
+	for _, r := range pattern {
+		if r != '*' && r != '?' && strings.ContainsRune(specialChars, r) {
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return &Text{Value: sb.String()}
+}
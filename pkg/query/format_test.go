@@ -0,0 +1,77 @@
+package query
+
+import (
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	type testCase struct {
+		Name     string
+		Input    string
+		Expected string
+	}
+
+	cases := []testCase{
+		{
+			Name:     "already-canonical",
+			Input:    "RequestLoggingMiddleware env:prod service:feserver* @handler_module:*bert* -@http.method:GET",
+			Expected: "RequestLoggingMiddleware env:prod service:feserver* @handler_module:*bert* -@http.method:GET",
+		},
+		{
+			Name:     "operators",
+			Input:    "service:foo AND NOT status:info AND env:prod",
+			Expected: "env:prod service:foo -status:info",
+		},
+		{
+			Name:     "facet-order",
+			Input:    "@http.status_code:500 -@http.method:HEAD service:foo error @http.method:GET",
+			Expected: "error service:foo -@http.method:HEAD @http.method:GET @http.status_code:500",
+		},
+		{
+			Name:     "quotes",
+			Input:    `@user:"alice" @msg:"hello world" @path:"/api/v1" status:"OR"`,
+			Expected: `status:"OR" @msg:"hello world" @path:"/api/v1" @user:alice`,
+		},
+		{
+			Name:     "quoted-lowercase-operators",
+			Input:    `"foo" "or" @op:"and" "Not"`,
+			Expected: `foo "or" "Not" @op:"and"`,
+		},
+		{
+			Name:     "redundant-parentheses",
+			Input:    "((service:foo)) (a OR (b OR c)) env:(prod) (x y)",
+			Expected: "x y env:prod service:foo (a OR b OR c)",
+		},
+		{
+			Name:     "needed-parentheses",
+			Input:    "-(a OR b) env:(prod OR staging) (a b) OR c",
+			Expected: "a b env:(prod OR staging) -(a OR b) OR c",
+		},
+		{
+			Name:     "double-negation",
+			Input:    "NOT -service:foo",
+			Expected: "service:foo",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			actual, err := Format(c.Input)
+			if err != nil {
+				t.Fatalf("Failed to format %v: %v", c.Input, err)
+			}
+			if actual != c.Expected {
+				t.Errorf("Got %v;\n Want %v", actual, c.Expected)
+			}
+
+			// Formatting is idempotent
+			again, err := Format(actual)
+			if err != nil {
+				t.Fatalf("Failed to format %v: %v", actual, err)
+			}
+			if again != actual {
+				t.Errorf("Formatting isn't idempotent; got %v; want %v", again, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package query
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Rule IDs reported by Lint.
+const (
+	// RuleLowercaseOperator flags and, or and not written in lowercase; Datadog treats them as free text.
+	RuleLowercaseOperator = "lowercase-operator"
+	// RuleUnquotedSpaces flags a field followed by free text which usually means the value contains spaces
+	// and should have been quoted e.g. @msg:connection reset.
+	RuleUnquotedSpaces = "unquoted-spaces"
+	// RuleStatusCase flags status values that aren't lowercase e.g. status:ERROR.
+	RuleStatusCase = "status-case"
+	// RuleFacetPrefix flags attributes that are missing the @ prefix e.g. http.status_code:500.
+	RuleFacetPrefix = "facet-prefix"
+	// RuleLeadingWildcard flags free text and message patterns starting with a wildcard which have to scan the
+	// whole message. Facet values are short so a leading wildcard on a facet is fine. It has no fix because removing the wildcard changes what the pattern matches.
+	RuleLeadingWildcard = "leading-wildcard"
+	// RuleContradiction flags a term that is both required and excluded e.g. env:prod -env:prod.
+	RuleContradiction = "contradiction"
+)
+
+var (
+	// LogRules are the rules that only apply to log queries e.g. because they depend on the reserved attributes
+	// of logs.
+	LogRules = map[string]bool{
+		RuleStatusCase:      true,
+		RuleLeadingWildcard: true,
+	}
+)
+
+// Finding is a likely mistake in a query.
+type Finding struct {
+	// Rule is the ID of the rule that produced the finding.
+	Rule string
+	// Span is the location of the problem in the query.
+	Span
+	Message string
+	// Fix is the suggested replacement for the text in Span. It is only set if HasFix is true; an empty Fix
+	// removes the text.
+	Fix    string
+	HasFix bool
+}
+
+// Column returns the 1 based column of the finding in the query.
+func (f Finding) Column(q string) int {
+	return column(q, f.Start)
+}
+
+// ApplyFix returns the query with the suggested fix applied. The query is unchanged if there is no fix.
+func (f Finding) ApplyFix(q string) string {
+	if !f.HasFix {
+		return q
+	}
+	return q[:f.Start] + f.Fix + q[f.Stop:]
+}
+
+// Lint parses the query and returns the mistakes that make it silently return the wrong results.
+// Findings are ordered by their position in the query. An error is returned if the query doesn't parse.
+func Lint(q string) ([]Finding, error) {
+	n, err := Parse(q)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &linter{query: q}
+	l.visit(n, nil)
+	sort.SliceStable(l.findings, func(i, j int) bool {
+		return l.findings[i].Start < l.findings[j].Start
+	})
+	return l.findings, nil
+}
+
+type linter struct {
+	query    string
+	findings []Finding
+}
+
+func (l *linter) add(rule string, span Span, fix string, format string, args ...any) {
+	l.findings = append(l.findings, Finding{
+		Rule:    rule,
+		Span:    span,
+		Message: fmt.Sprintf(format, args...),
+		Fix:     fix,
+		HasFix:  true,
+	})
+}
+
+// report adds a finding that has no suggested fix.
+func (l *linter) report(rule string, span Span, format string, args ...any) {
+	l.findings = append(l.findings, Finding{
+		Rule:    rule,
+		Span:    span,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// visit lints the node. field is the field whose value contains the node or nil if the node isn't part of a value.
+func (l *linter) visit(n Node, field *Field) {
+	switch v := n.(type) {
+	case *Text:
+		l.lintText(v, field)
+	case *Field:
+		if !v.IsFacet() && strings.Contains(v.Key, ".") {
+			span := Span{Start: v.Pos(), Stop: v.Pos() + len(v.Key)}
+			l.add(RuleFacetPrefix, span, "@"+v.Key, "%v looks like an attribute; facets must be prefixed with @ or they are matched as tags", v.Key)
+		}
+		l.visit(v.Value, v)
+	case *Not:
+		l.visit(v.Operand, field)
+	case *And:
+		l.lintContradictions(v)
+		if v.Implicit && field == nil {
+			l.lintUnquotedSpaces(v)
+		}
+		for _, o := range v.Operands {
+			l.visit(o, field)
+		}
+	case *Or:
+		for _, o := range v.Operands {
+			l.visit(o, field)
+		}
+	case *Group:
+		l.visit(v.Expr, field)
+	}
+}
+
+// isMessageField returns true if the key is the message of a log.
+func isMessageField(key string) bool {
+	return key == "message" || key == "@message"
+}
+
+func (l *linter) lintText(t *Text, field *Field) {
+	if t.Quoted {
+		return
+	}
+
+	// The value of a field can be any word e.g. @operator:and; only words between terms are operators.
+	if isLowercaseOperator(t.Value) && (field == nil || field.Value != t) {
+		upper := strings.ToUpper(t.Value)
+		l.add(RuleLowercaseOperator, t.Span, upper, "%v is matched as free text; operators must be uppercase e.g. %v", t.Value, upper)
+	}
+
+	if len(t.Value) > 1 && strings.ContainsAny(t.Value[:1], "*?") && (field == nil || isMessageField(field.Key)) {
+		l.report(RuleLeadingWildcard, t.Span, "%v starts with a wildcard which is slow on large fields such as the message; anchor the pattern at the start or match a facet instead", t.Value)
+	}
+
+	if field != nil && field.Key == "status" && t.Value != strings.ToLower(t.Value) {
+		lower := strings.ToLower(t.Value)
+		l.add(RuleStatusCase, t.Span, lower, "status values are lowercase; use status:%v", lower)
+	}
+}
+
+// lintContradictions flags negated terms that are also required by the AND so the AND can't match anything.
+// Terms are compared in canonical form so env:prod contradicts -env:"prod".
+func (l *linter) lintContradictions(a *And) {
+	required := map[string]bool{}
+	for _, o := range a.Operands {
+		if _, ok := o.(*Not); !ok {
+			required[canonical(o)] = true
+		}
+	}
+
+	for _, o := range a.Operands {
+		not, ok := o.(*Not)
+		if !ok {
+			continue
+		}
+		term := Print(not.Operand)
+		if required[canonical(not.Operand)] {
+			l.add(RuleContradiction, not.Span, "", "%v is both required and excluded so the query can't match any logs", term)
+		}
+	}
+}
+
+// lintUnquotedSpaces flags a field whose value is followed by free text. Free text is normally written before
+// the fields so words following a field are usually the rest of a value containing spaces.
+func (l *linter) lintUnquotedSpaces(a *And) {
+	for i := 0; i < len(a.Operands); i++ {
+		if _, ok := a.Operands[i].(*Text); ok {
+			// The query has free text before the fields so it can't be told apart from an unquoted value.
+			return
+		}
+
+		f, ok := a.Operands[i].(*Field)
+		if !ok {
+			continue
+		}
+		value, ok := f.Value.(*Text)
+		if !ok || value.Quoted || value.HasWildcard() {
+			continue
+		}
+
+		words := []string{value.Value}
+		last := value.Span
+		for j := i + 1; j < len(a.Operands); j++ {
+			t, ok := a.Operands[j].(*Text)
+			if !ok || t.Quoted || isLowercaseOperator(t.Value) {
+				break
+			}
+			words = append(words, t.Value)
+			last = t.Span
+			i = j
+		}
+
+		if len(words) == 1 {
+			continue
+		}
+		phrase := strings.Join(words, " ")
+		l.add(RuleUnquotedSpaces, Span{Start: value.Pos(), Stop: last.End()}, quotePhrase(phrase), "%v only matches %v and the rest is free text; quote values containing spaces", f.Key, value.Value)
+	}
+}
+
+func isLowercaseOperator(word string) bool {
+	for _, kw := range []string{keywordAnd, keywordOr, keywordNot} {
+		if word != kw && strings.EqualFold(word, kw) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLint(t *testing.T) {
+	type expected struct {
+		Rule   string
+		Column int
+		Fixed  string
+	}
+
+	type testCase struct {
+		Name     string
+		Input    string
+		Expected []expected
+	}
+
+	cases := []testCase{
+		{
+			Name:     "clean",
+			Input:    `RequestLoggingMiddleware env:prod service:feserver* @http.status_code:>=500 -@http.method:GET`,
+			Expected: nil,
+		},
+		{
+			Name:  "lowercase-operator",
+			Input: "service:web or service:api",
+			Expected: []expected{
+				{Rule: RuleLowercaseOperator, Column: 13, Fixed: "service:web OR service:api"},
+			},
+		},
+		{
+			Name:  "lowercase-operator-in-value",
+			Input: "env:(prod or staging) @operator:and",
+			Expected: []expected{
+				{Rule: RuleLowercaseOperator, Column: 11, Fixed: "env:(prod OR staging) @operator:and"},
+			},
+		},
+		{
+			Name:  "unquoted-spaces",
+			Input: "service:web @error.message:connection reset by peer",
+			Expected: []expected{
+				{Rule: RuleUnquotedSpaces, Column: 28, Fixed: `service:web @error.message:"connection reset by peer"`},
+			},
+		},
+		{
+			Name:     "free-text-first",
+			Input:    "timeout service:web retry",
+			Expected: nil,
+		},
+		{
+			Name:  "status-case",
+			Input: "status:ERROR",
+			Expected: []expected{
+				{Rule: RuleStatusCase, Column: 8, Fixed: "status:error"},
+			},
+		},
+		{
+			Name:  "facet-prefix",
+			Input: "http.status_code:500",
+			Expected: []expected{
+				{Rule: RuleFacetPrefix, Column: 1, Fixed: "@http.status_code:500"},
+			},
+		},
+		{
+			Name:  "leading-wildcard",
+			Input: "*timeout @path:* message:*bert",
+			Expected: []expected{
+				{Rule: RuleLeadingWildcard, Column: 1, Fixed: "*timeout @path:* message:*bert"},
+				{Rule: RuleLeadingWildcard, Column: 26, Fixed: "*timeout @path:* message:*bert"},
+			},
+		},
+		{
+			Name:  "leading-wildcard-facet",
+			Input: "@http.url:*foo @handler:?bert",
+		},
+		{
+			Name:  "contradiction",
+			Input: "env:prod service:web -env:prod",
+			Expected: []expected{
+				{Rule: RuleContradiction, Column: 22, Fixed: "env:prod service:web "},
+			},
+		},
+		{
+			Name:  "contradiction-quoted",
+			Input: `env:prod -env:"prod"`,
+			Expected: []expected{
+				{Rule: RuleContradiction, Column: 10, Fixed: "env:prod "},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			findings, err := Lint(c.Input)
+			if err != nil {
+				t.Fatalf("Failed to lint %v: %v", c.Input, err)
+			}
+
+			var actual []expected
+			for _, f := range findings {
+				actual = append(actual, expected{Rule: f.Rule, Column: f.Column(c.Input), Fixed: f.ApplyFix(c.Input)})
+			}
+
+			if d := cmp.Diff(c.Expected, actual); d != "" {
+				t.Errorf("Findings don't match; diff\n%v", d)
+			}
+		})
+	}
+}
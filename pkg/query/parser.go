@@ -0,0 +1,419 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	keywordAnd = "AND"
+	keywordOr  = "OR"
+	keywordNot = "NOT"
+	keywordTo  = "TO"
+)
+
+// ParseError is returned when a query isn't valid.
+type ParseError struct {
+	// Query is the query that failed to parse
+	Query string
+	// Offset is the byte offset in the query where the error was detected
+	Offset int
+	// Msg describes the error
+	Msg string
+}
+
+// Column returns the 1 based column of the error in the query.
+func (e *ParseError) Column() int {
+	return column(e.Query, e.Offset)
+}
+
+// column returns the 1 based column of the byte offset in the query.
+func column(q string, offset int) int {
+	return utf8.RuneCountInString(q[:offset]) + 1
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("syntax error at column %d: %s", e.Column(), e.Msg)
+}
+
+// Context returns the query followed by a line with a caret pointing at the error.
+func (e *ParseError) Context() string {
+	return e.Query + "\n" + strings.Repeat(" ", e.Column()-1) + "^"
+}
+
+// Parse parses a Datadog log search query. It returns a nil Node for an empty query.
+//
+// Operators are case sensitive; lowercase and, or and not are free text just as they are in Datadog.
+// AND binds more tightly than OR and whitespace between terms is an implicit AND.
+func Parse(q string) (Node, error) {
+	p := &parser{query: q}
+	p.skipSpace()
+	if p.eof() {
+		return nil, nil
+	}
+
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpace()
+	if !p.eof() {
+		return nil, p.errorf(p.pos, "unexpected %q", p.peek())
+	}
+	return n, nil
+}
+
+// MustParse is like Parse but panics if the query isn't valid. It is intended for tests and constant queries.
+func MustParse(q string) Node {
+	n, err := Parse(q)
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+type parser struct {
+	query string
+	pos   int
+	// inValue is true while parsing the values of a field e.g. the contents of env:(prod OR staging).
+	// Values can contain colons and can't be fields.
+	inValue bool
+}
+
+func (p *parser) errorf(offset int, format string, args ...any) *ParseError {
+	return &ParseError{
+		Query:  p.query,
+		Offset: offset,
+		Msg:    fmt.Sprintf(format, args...),
+	}
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.query)
+}
+
+func (p *parser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.query[p.pos]
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func (p *parser) skipSpace() {
+	for !p.eof() && isSpace(p.peek()) {
+		p.pos++
+	}
+}
+
+// peekKeyword returns true if the keyword is at the current position and is followed by a delimiter.
+func (p *parser) peekKeyword(kw string) bool {
+	if !strings.HasPrefix(p.query[p.pos:], kw) {
+		return false
+	}
+	next := p.pos + len(kw)
+	if next == len(p.query) {
+		return true
+	}
+	c := p.query[next]
+	return isSpace(c) || c == '(' || c == ')' || c == '"'
+}
+
+// atTermEnd returns true if there are no more terms in the current expression.
+func (p *parser) atTermEnd() bool {
+	return p.eof() || p.peek() == ')'
+}
+
+func (p *parser) parseOr() (Node, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	operands := []Node{first}
+
+	for {
+		p.skipSpace()
+		if !p.peekKeyword(keywordOr) {
+			break
+		}
+		kwPos := p.pos
+		p.pos += len(keywordOr)
+		p.skipSpace()
+		if p.atTermEnd() {
+			return nil, p.errorf(kwPos, "expected a term after OR")
+		}
+		n, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, n)
+	}
+
+	if len(operands) == 1 {
+		return first, nil
+	}
+	return &Or{
+		Span:     Span{Start: first.Pos(), Stop: operands[len(operands)-1].End()},
+		Operands: operands,
+	}, nil
+}
+
+func (p *parser) parseAnd() (Node, error) {
+	first, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	operands := []Node{first}
+	explicit := false
+
+	for {
+		p.skipSpace()
+		if p.atTermEnd() || p.peekKeyword(keywordOr) {
+			break
+		}
+
+		if p.peekKeyword(keywordAnd) {
+			kwPos := p.pos
+			p.pos += len(keywordAnd)
+			p.skipSpace()
+			if p.atTermEnd() || p.peekKeyword(keywordOr) || p.peekKeyword(keywordAnd) {
+				return nil, p.errorf(kwPos, "expected a term after AND")
+			}
+			explicit = true
+		}
+
+		n, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, n)
+	}
+
+	if len(operands) == 1 {
+		return first, nil
+	}
+	return &And{
+		Span:     Span{Start: first.Pos(), Stop: operands[len(operands)-1].End()},
+		Operands: operands,
+		Implicit: !explicit,
+	}, nil
+}
+
+func (p *parser) parseUnary() (Node, error) {
+	start := p.pos
+	if p.peek() == '-' {
+		p.pos++
+		if p.eof() || isSpace(p.peek()) || p.peek() == ')' {
+			return nil, p.errorf(start, "expected a term after '-'")
+		}
+		operand, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		return &Not{Span: Span{Start: start, Stop: operand.End()}, Operand: operand}, nil
+	}
+
+	if p.peekKeyword(keywordNot) {
+		p.pos += len(keywordNot)
+		p.skipSpace()
+		if p.atTermEnd() {
+			return nil, p.errorf(start, "expected a term after NOT")
+		}
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &Not{Span: Span{Start: start, Stop: operand.End()}, Operand: operand, Keyword: true}, nil
+	}
+
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Node, error) {
+	start := p.pos
+	switch p.peek() {
+	case '(':
+		return p.parseGroup()
+	case ')':
+		return nil, p.errorf(start, "unexpected ')'")
+	case '"':
+		return p.parseQuoted()
+	}
+
+	for _, kw := range []string{keywordAnd, keywordOr} {
+		if p.peekKeyword(kw) {
+			return nil, p.errorf(start, "unexpected %v; expected a term", kw)
+		}
+	}
+
+	word := p.readWord(p.inValue)
+	if word == "" {
+		return nil, p.errorf(start, "unexpected %q", p.peek())
+	}
+
+	if !p.inValue && p.peek() == ':' {
+		p.pos++
+		value, err := p.parseFieldValue()
+		if err != nil {
+			return nil, err
+		}
+		return &Field{Span: Span{Start: start, Stop: value.End()}, Key: word, Value: value}, nil
+	}
+
+	return &Text{Span: Span{Start: start, Stop: p.pos}, Value: word}, nil
+}
+
+func (p *parser) parseGroup() (Node, error) {
+	start := p.pos
+	p.pos++
+	p.skipSpace()
+	if p.peek() == ')' {
+		return nil, p.errorf(start, "empty parentheses")
+	}
+	if p.eof() {
+		return nil, p.errorf(start, "missing ')' to close '('")
+	}
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.peek() != ')' {
+		return nil, p.errorf(start, "missing ')' to close '('")
+	}
+	p.pos++
+	return &Group{Span: Span{Start: start, Stop: p.pos}, Expr: expr}, nil
+}
+
+func (p *parser) parseQuoted() (*Text, error) {
+	start := p.pos
+	p.pos++
+	var sb strings.Builder
+	for {
+		if p.eof() {
+			return nil, p.errorf(start, "unterminated quoted phrase")
+		}
+		c := p.peek()
+		p.pos++
+		switch c {
+		case '\\':
+			if p.eof() {
+				return nil, p.errorf(start, "unterminated quoted phrase")
+			}
+			sb.WriteByte(p.peek())
+			p.pos++
+		case '"':
+			return &Text{Span: Span{Start: start, Stop: p.pos}, Value: sb.String(), Quoted: true}, nil
+		default:
+			sb.WriteByte(c)
+		}
+	}
+}
+
+// readWord reads an unquoted word. The word ends at whitespace, a parenthesis, a quote or, unless allowColon
+// is true, an unescaped colon. Escape sequences are kept in the word.
+func (p *parser) readWord(allowColon bool) string {
+	start := p.pos
+	for !p.eof() {
+		c := p.peek()
+		if isSpace(c) || c == '(' || c == ')' || c == '"' || (c == ':' && !allowColon) {
+			break
+		}
+		if c == '\\' && p.pos+1 < len(p.query) {
+			p.pos++
+		}
+		p.pos++
+	}
+	return p.query[start:p.pos]
+}
+
+func (p *parser) parseFieldValue() (Node, error) {
+	start := p.pos
+	if p.eof() || isSpace(p.peek()) || p.peek() == ')' {
+		return nil, p.errorf(start, "expected a value after ':'")
+	}
+
+	switch p.peek() {
+	case '(':
+		inValue := p.inValue
+		p.inValue = true
+		defer func() { p.inValue = inValue }()
+		return p.parseGroup()
+	case '[', '{':
+		return p.parseRange()
+	case '>', '<':
+		op := string(p.peek())
+		p.pos++
+		if p.peek() == '=' {
+			op += "="
+			p.pos++
+		}
+		value := p.readWord(true)
+		if value == "" {
+			return nil, p.errorf(p.pos, "expected a value after %v", op)
+		}
+		return &Comparison{Span: Span{Start: start, Stop: p.pos}, Op: op, Value: value}, nil
+	case '"':
+		return p.parseQuoted()
+	}
+
+	value := p.readWord(true)
+	if value == "" {
+		return nil, p.errorf(start, "unexpected %q; expected a value after ':'", p.peek())
+	}
+	return &Text{Span: Span{Start: start, Stop: p.pos}, Value: value}, nil
+}
+
+func (p *parser) parseRange() (Node, error) {
+	start := p.pos
+	r := &Range{ExclusiveLow: p.peek() == '{'}
+	p.pos++
+
+	p.skipSpace()
+	r.Low = p.readRangeBound()
+	if r.Low == "" {
+		return nil, p.errorf(p.pos, "expected the lower bound of the range")
+	}
+
+	p.skipSpace()
+	if !p.peekKeyword(keywordTo) {
+		return nil, p.errorf(p.pos, "expected TO in range")
+	}
+	p.pos += len(keywordTo)
+
+	p.skipSpace()
+	r.High = p.readRangeBound()
+	if r.High == "" {
+		return nil, p.errorf(p.pos, "expected the upper bound of the range")
+	}
+
+	p.skipSpace()
+	switch p.peek() {
+	case ']':
+	case '}':
+		r.ExclusiveHigh = true
+	default:
+		return nil, p.errorf(p.pos, "expected ']' or '}' to close the range")
+	}
+	p.pos++
+	r.Span = Span{Start: start, Stop: p.pos}
+	return r, nil
+}
+
+func (p *parser) readRangeBound() string {
+	start := p.pos
+	for !p.eof() {
+		c := p.peek()
+		if isSpace(c) || c == ']' || c == '}' {
+			break
+		}
+		p.pos++
+	}
+	return p.query[start:p.pos]
+}
@@ -0,0 +1,250 @@
+package query
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParse(t *testing.T) {
+	type testCase struct {
+		Name     string
+		Input    string
+		Expected Node
+	}
+
+	cases := []testCase{
+		{
+			Name:     "empty",
+			Input:    "  ",
+			Expected: nil,
+		},
+		{
+			Name:     "free-text",
+			Input:    "RequestLoggingMiddleware",
+			Expected: &Text{Span: Span{0, 24}, Value: "RequestLoggingMiddleware"},
+		},
+		{
+			Name:  "tag-and-facet",
+			Input: "env:prod @http.status_code:500",
+			Expected: &And{
+				Span: Span{0, 30},
+				Operands: []Node{
+					&Field{Span: Span{0, 8}, Key: "env", Value: &Text{Span: Span{4, 8}, Value: "prod"}},
+					&Field{Span: Span{9, 30}, Key: "@http.status_code", Value: &Text{Span: Span{27, 30}, Value: "500"}},
+				},
+				Implicit: true,
+			},
+		},
+		{
+			Name:  "negation",
+			Input: `-@http.method:GET NOT "hello world"`,
+			Expected: &And{
+				Span: Span{0, 35},
+				Operands: []Node{
+					&Not{Span: Span{0, 17}, Operand: &Field{Span: Span{1, 17}, Key: "@http.method", Value: &Text{Span: Span{14, 17}, Value: "GET"}}},
+					&Not{Span: Span{18, 35}, Operand: &Text{Span: Span{22, 35}, Value: "hello world", Quoted: true}, Keyword: true},
+				},
+				Implicit: true,
+			},
+		},
+		{
+			Name:  "range-and-comparison",
+			Input: "@duration:[100 TO 200} @http.status_code:>=500",
+			Expected: &And{
+				Span: Span{0, 46},
+				Operands: []Node{
+					&Field{Span: Span{0, 22}, Key: "@duration", Value: &Range{Span: Span{10, 22}, Low: "100", High: "200", ExclusiveHigh: true}},
+					&Field{Span: Span{23, 46}, Key: "@http.status_code", Value: &Comparison{Span: Span{41, 46}, Op: ">=", Value: "500"}},
+				},
+				Implicit: true,
+			},
+		},
+		{
+			Name:  "precedence",
+			Input: "a OR b AND c",
+			Expected: &Or{
+				Span: Span{0, 12},
+				Operands: []Node{
+					&Text{Span: Span{0, 1}, Value: "a"},
+					&And{
+						Span:     Span{5, 12},
+						Operands: []Node{&Text{Span: Span{5, 6}, Value: "b"}, &Text{Span: Span{11, 12}, Value: "c"}},
+					},
+				},
+			},
+		},
+		{
+			Name:  "field-group",
+			Input: "env:(prod OR staging)",
+			Expected: &Field{
+				Span: Span{0, 21},
+				Key:  "env",
+				Value: &Group{
+					Span: Span{4, 21},
+					Expr: &Or{
+						Span:     Span{5, 20},
+						Operands: []Node{&Text{Span: Span{5, 9}, Value: "prod"}, &Text{Span: Span{13, 20}, Value: "staging"}},
+					},
+				},
+			},
+		},
+		{
+			Name:  "lowercase-operators-are-text",
+			Input: "a or b",
+			Expected: &And{
+				Span:     Span{0, 6},
+				Operands: []Node{&Text{Span: Span{0, 1}, Value: "a"}, &Text{Span: Span{2, 4}, Value: "or"}, &Text{Span: Span{5, 6}, Value: "b"}},
+				Implicit: true,
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			actual, err := Parse(c.Input)
+			if err != nil {
+				t.Fatalf("Failed to parse %v: %v", c.Input, err)
+			}
+
+			if d := cmp.Diff(c.Expected, actual); d != "" {
+				t.Errorf("AST doesn't match; diff\n%v", d)
+			}
+		})
+	}
+}
+
+func TestPrint(t *testing.T) {
+	type testCase struct {
+		Name     string
+		Input    string
+		Expected string
+	}
+
+	cases := []testCase{
+		{
+			Name:     "basic",
+			Input:    "RequestLoggingMiddleware env:prod service:feserver* @handler_module:*bert* -@http.method:GET -@http.method:HEAD status:error -@handler_module:*laxmod* -@handler:*laxmod*",
+			Expected: "RequestLoggingMiddleware env:prod service:feserver* @handler_module:*bert* -@http.method:GET -@http.method:HEAD status:error -@handler_module:*laxmod* -@handler:*laxmod*",
+		},
+		{
+			Name:     "whitespace",
+			Input:    "  service:foo    AND   ( a  OR b )  ",
+			Expected: "service:foo AND (a OR b)",
+		},
+		{
+			Name:     "quotes-and-escapes",
+			Input:    `@msg:"say \"hi\"" @path:\/api\/v1 @url:http://example.com`,
+			Expected: `@msg:"say \"hi\"" @path:\/api\/v1 @url:http://example.com`,
+		},
+		{
+			Name:     "ranges",
+			Input:    "@duration:{ 1 TO * ] @count:<10",
+			Expected: "@duration:{1 TO *] @count:<10",
+		},
+		{
+			Name:     "nested-not",
+			Input:    "NOT (a OR b) -(c d)",
+			Expected: "NOT (a OR b) -(c d)",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			n, err := Parse(c.Input)
+			if err != nil {
+				t.Fatalf("Failed to parse %v: %v", c.Input, err)
+			}
+
+			actual := Print(n)
+			if actual != c.Expected {
+				t.Errorf("Got %v;\n Want %v", actual, c.Expected)
+			}
+
+			// Printing is idempotent
+			again, err := Parse(actual)
+			if err != nil {
+				t.Fatalf("Failed to parse printed query %v: %v", actual, err)
+			}
+			if Print(again) != actual {
+				t.Errorf("Printing isn't idempotent; got %v; want %v", Print(again), actual)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	type testCase struct {
+		Name           string
+		Input          string
+		ExpectedColumn int
+		ExpectedMsg    string
+	}
+
+	cases := []testCase{
+		{
+			Name:           "unclosed-paren",
+			Input:          "service:foo (a OR b",
+			ExpectedColumn: 13,
+			ExpectedMsg:    "missing ')' to close '('",
+		},
+		{
+			Name:           "extra-paren",
+			Input:          "a b)",
+			ExpectedColumn: 4,
+			ExpectedMsg:    `unexpected ')'`,
+		},
+		{
+			Name:           "missing-value",
+			Input:          "env: prod",
+			ExpectedColumn: 5,
+			ExpectedMsg:    "expected a value after ':'",
+		},
+		{
+			Name:           "dangling-or",
+			Input:          "a OR",
+			ExpectedColumn: 3,
+			ExpectedMsg:    "expected a term after OR",
+		},
+		{
+			Name:           "unterminated-quote",
+			Input:          `@msg:"hello`,
+			ExpectedColumn: 6,
+			ExpectedMsg:    "unterminated quoted phrase",
+		},
+		{
+			Name:           "range-without-to",
+			Input:          "@duration:[1 2]",
+			ExpectedColumn: 14,
+			ExpectedMsg:    "expected TO in range",
+		},
+		{
+			Name:           "leading-and",
+			Input:          "AND a",
+			ExpectedColumn: 1,
+			ExpectedMsg:    "unexpected AND; expected a term",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			_, err := Parse(c.Input)
+			if err == nil {
+				t.Fatalf("Expected an error parsing %v", c.Input)
+			}
+
+			var pErr *ParseError
+			if !errors.As(err, &pErr) {
+				t.Fatalf("Expected a ParseError but got %T", err)
+			}
+
+			if pErr.Column() != c.ExpectedColumn {
+				t.Errorf("Column doesn't match; got %v; want %v\n%v", pErr.Column(), c.ExpectedColumn, pErr.Context())
+			}
+			if pErr.Msg != c.ExpectedMsg {
+				t.Errorf("Message doesn't match; got %v; want %v", pErr.Msg, c.ExpectedMsg)
+			}
+		})
+	}
+}
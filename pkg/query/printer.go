@@ -0,0 +1,101 @@
+package query
+
+import (
+	"strings"
+)
+
+// Print returns the canonical string for the query. It returns an empty string for an empty query.
+func Print(n Node) string {
+	if n == nil {
+		return ""
+	}
+	return n.String()
+}
+
+func (t *Text) String() string {
+	if t.Quoted {
+		return quotePhrase(t.Value)
+	}
+	return t.Value
+}
+
+func (f *Field) String() string {
+	if f.Value == nil {
+		return f.Key + ":"
+	}
+	return f.Key + ":" + f.Value.String()
+}
+
+func (r *Range) String() string {
+	open := "["
+	if r.ExclusiveLow {
+		open = "{"
+	}
+	closing := "]"
+	if r.ExclusiveHigh {
+		closing = "}"
+	}
+	return open + r.Low + " TO " + r.High + closing
+}
+
+func (c *Comparison) String() string {
+	return c.Op + c.Value
+}
+
+func (n *Not) String() string {
+	operand := Print(n.Operand)
+	switch n.Operand.(type) {
+	case *And, *Or:
+		// Parenthesize so the negation applies to the whole expression.
+		operand = "(" + operand + ")"
+	}
+
+	if n.Keyword {
+		return "NOT " + operand
+	}
+	return "-" + operand
+}
+
+func (a *And) String() string {
+	sep := " AND "
+	if a.Implicit {
+		sep = " "
+	}
+
+	parts := make([]string, 0, len(a.Operands))
+	for _, o := range a.Operands {
+		s := Print(o)
+		if _, ok := o.(*Or); ok {
+			// AND binds more tightly than OR so an OR operand must be parenthesized.
+			s = "(" + s + ")"
+		}
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, sep)
+}
+
+func (o *Or) String() string {
+	parts := make([]string, 0, len(o.Operands))
+	for _, operand := range o.Operands {
+		parts = append(parts, Print(operand))
+	}
+	return strings.Join(parts, " OR ")
+}
+
+func (g *Group) String() string {
+	return "(" + Print(g.Expr) + ")"
+}
+
+// quotePhrase returns value as a quoted phrase escaping any quotes and backslashes.
+func quotePhrase(value string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, r := range value {
+		if r == '"' || r == '\\' {
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}